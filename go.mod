@@ -2,7 +2,11 @@ module github.com/christwosix/gohttp
 
 go 1.20
 
-require github.com/stretchr/testify v1.8.3
+require (
+	github.com/andybalholm/brotli v1.1.1
+	github.com/klauspost/compress v1.17.9
+	github.com/stretchr/testify v1.8.3
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
@@ -0,0 +1,207 @@
+package goclient
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultBreakerFailureThreshold = 0.5
+	defaultBreakerMinRequests      = 10
+	defaultBreakerInterval         = time.Minute
+	defaultBreakerOpenDuration     = 30 * time.Second
+	defaultBreakerHalfOpenProbes   = 1
+)
+
+// CircuitBreakerPolicy defines when the circuit breaker of a host opens and
+// how it recovers. While the circuit of a host is open, requests to it fail
+// fast with an ErrCircuitOpen error instead of waiting for a timeout.
+type CircuitBreakerPolicy struct {
+	// FailureThreshold is the ratio of failed requests, between 0 and 1, at
+	// which the circuit opens. A request fails if the connection fails or the
+	// web service responds with a 5xx status code. Defaults to 0.5.
+	FailureThreshold float64
+
+	// MinRequests is the min number of requests within an interval before the
+	// failure ratio is considered. Defaults to 10.
+	MinRequests int
+
+	// Interval is the duration after which the request counts of a closed
+	// circuit are cleared. Defaults to 1m.
+	Interval time.Duration
+
+	// OpenDuration is the duration that a circuit stays open before probe
+	// requests are let through. Defaults to 30s.
+	OpenDuration time.Duration
+
+	// HalfOpenProbes is the number of probe requests let through a half-open
+	// circuit. The circuit closes once all of them succeed and opens again
+	// as soon as one of them fails. Defaults to 1.
+	HalfOpenProbes int
+}
+
+// circuitState represents the state of the circuit of a host.
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitResult represents the outcome of a request let through a circuit.
+type circuitResult int
+
+const (
+	circuitSuccess circuitResult = iota
+	circuitFailure
+	circuitIgnored
+)
+
+// circuit holds the state and request counts of a single host. The generation
+// changes with every change of state or cleared counts, so that the result of
+// a request let through before is not counted against the current state.
+type circuit struct {
+	state      circuitState
+	generation uint64
+	expiry     time.Time
+	requests   int
+	failures   int
+	probes     int
+	successes  int
+}
+
+// circuitBreaker keeps track of the circuits of each host. It is concurrent
+// safe.
+type circuitBreaker struct {
+	policy   CircuitBreakerPolicy
+	now      func() time.Time
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// newCircuitBreaker returns a circuit breaker with the defaults applied to any
+// unset values of the policy.
+func newCircuitBreaker(policy CircuitBreakerPolicy) *circuitBreaker {
+	if policy.FailureThreshold <= 0 {
+		policy.FailureThreshold = defaultBreakerFailureThreshold
+	}
+	if policy.MinRequests <= 0 {
+		policy.MinRequests = defaultBreakerMinRequests
+	}
+	if policy.Interval <= 0 {
+		policy.Interval = defaultBreakerInterval
+	}
+	if policy.OpenDuration <= 0 {
+		policy.OpenDuration = defaultBreakerOpenDuration
+	}
+	if policy.HalfOpenProbes <= 0 {
+		policy.HalfOpenProbes = defaultBreakerHalfOpenProbes
+	}
+	return &circuitBreaker{
+		policy:   policy,
+		now:      time.Now,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// allow returns an ErrCircuitOpen error if a request to the host must be
+// rejected. Otherwise, the request is let through and its result must be
+// recorded with the returned generation of the circuit.
+func (b *circuitBreaker) allow(host string) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	cb, ok := b.circuits[host]
+	if !ok {
+		cb = &circuit{expiry: now.Add(b.policy.Interval)}
+		b.circuits[host] = cb
+	}
+
+	switch cb.state {
+	case circuitClosed:
+		if !now.Before(cb.expiry) {
+			b.setState(cb, circuitClosed, now)
+		}
+	case circuitOpen:
+		if now.Before(cb.expiry) {
+			return 0, &ErrCircuitOpen{Host: host, RetryAt: cb.expiry}
+		}
+		b.setState(cb, circuitHalfOpen, now)
+		fallthrough
+	case circuitHalfOpen:
+		if cb.probes >= b.policy.HalfOpenProbes {
+			return 0, &ErrCircuitOpen{Host: host, RetryAt: cb.expiry}
+		}
+		cb.probes++
+	}
+	return cb.generation, nil
+}
+
+// record updates the circuit of the host with the result of a request that
+// was let through. The result is discarded if the circuit has changed state
+// or cleared its counts since the request was let through, such as a slow
+// request let through a closed circuit that completes while it is half-open.
+func (b *circuitBreaker) record(host string, generation uint64, result circuitResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cb, ok := b.circuits[host]
+	if !ok || cb.generation != generation {
+		return
+	}
+	now := b.now()
+
+	switch cb.state {
+	case circuitClosed:
+		if result == circuitIgnored {
+			return
+		}
+		cb.requests++
+		if result == circuitFailure {
+			cb.failures++
+		}
+		if cb.requests >= b.policy.MinRequests &&
+			float64(cb.failures)/float64(cb.requests) >= b.policy.FailureThreshold {
+			b.setState(cb, circuitOpen, now)
+		}
+	case circuitHalfOpen:
+		switch result {
+		case circuitFailure:
+			b.setState(cb, circuitOpen, now)
+		case circuitIgnored:
+			cb.probes--
+		default:
+			cb.successes++
+			if cb.successes >= b.policy.HalfOpenProbes {
+				b.setState(cb, circuitClosed, now)
+			}
+		}
+	}
+}
+
+// setState moves the circuit to the given state, clears its counts and starts
+// a new generation.
+func (b *circuitBreaker) setState(cb *circuit, state circuitState, now time.Time) {
+	*cb = circuit{state: state, generation: cb.generation + 1}
+	switch state {
+	case circuitClosed:
+		cb.expiry = now.Add(b.policy.Interval)
+	case circuitOpen:
+		cb.expiry = now.Add(b.policy.OpenDuration)
+	case circuitHalfOpen:
+		cb.expiry = now
+	}
+}
+
+// getCircuitBreaker returns the circuit breaker of the client or nil if one is
+// not defined as part of the client build.
+func (c *client) getCircuitBreaker() *circuitBreaker {
+	c.breakerOnce.Do(func() {
+		if c.builder.circuitBreaker != nil {
+			c.breaker = newCircuitBreaker(*c.builder.circuitBreaker)
+		}
+	})
+	return c.breaker
+}
@@ -0,0 +1,193 @@
+package goclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockClock provides a manually advanced clock for circuit breaker tests.
+type mockClock struct {
+	now time.Time
+}
+
+func (m *mockClock) Now() time.Time {
+	return m.now
+}
+
+func newMockBreaker(policy CircuitBreakerPolicy) (*circuitBreaker, *mockClock) {
+	clock := &mockClock{now: time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+	b := newCircuitBreaker(policy)
+	b.now = clock.Now
+	return b, clock
+}
+
+func TestNewCircuitBreaker(t *testing.T) {
+	b := newCircuitBreaker(CircuitBreakerPolicy{})
+	assert.Equal(t, 0.5, b.policy.FailureThreshold)
+	assert.Equal(t, 10, b.policy.MinRequests)
+	assert.Equal(t, time.Minute, b.policy.Interval)
+	assert.Equal(t, 30*time.Second, b.policy.OpenDuration)
+	assert.Equal(t, 1, b.policy.HalfOpenProbes)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	policy := CircuitBreakerPolicy{
+		FailureThreshold: 0.5,
+		MinRequests:      4,
+		Interval:         time.Minute,
+		OpenDuration:     10 * time.Second,
+		HalfOpenProbes:   2,
+	}
+
+	t.Run("BelowMinRequests", func(t *testing.T) {
+		b, _ := newMockBreaker(policy)
+		for i := 0; i < 3; i++ {
+			generation, err := b.allow("foobar.com")
+			require.NoError(t, err)
+			b.record("foobar.com", generation, circuitFailure)
+		}
+		_, err := b.allow("foobar.com")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Opens", func(t *testing.T) {
+		b, clock := newMockBreaker(policy)
+		for _, result := range []circuitResult{circuitSuccess, circuitFailure, circuitSuccess, circuitFailure} {
+			generation, err := b.allow("foobar.com")
+			require.NoError(t, err)
+			b.record("foobar.com", generation, result)
+		}
+
+		_, err := b.allow("foobar.com")
+		var circuitErr *ErrCircuitOpen
+		require.True(t, errors.As(err, &circuitErr))
+		assert.Equal(t, "foobar.com", circuitErr.Host)
+		assert.Equal(t, clock.now.Add(10*time.Second), circuitErr.RetryAt)
+
+		// Other hosts are unaffected.
+		_, err = b.allow("example.com")
+		assert.NoError(t, err)
+	})
+
+	t.Run("IntervalClearsCounts", func(t *testing.T) {
+		b, clock := newMockBreaker(policy)
+		for i := 0; i < 3; i++ {
+			generation, err := b.allow("foobar.com")
+			require.NoError(t, err)
+			b.record("foobar.com", generation, circuitFailure)
+		}
+		clock.now = clock.now.Add(time.Minute)
+		generation, err := b.allow("foobar.com")
+		require.NoError(t, err)
+		b.record("foobar.com", generation, circuitFailure)
+		_, err = b.allow("foobar.com")
+		assert.NoError(t, err)
+	})
+
+	t.Run("HalfOpenCloses", func(t *testing.T) {
+		b, clock := newMockBreaker(policy)
+		b.allow("foobar.com")
+		b.setState(b.circuits["foobar.com"], circuitOpen, clock.now)
+
+		clock.now = clock.now.Add(10 * time.Second)
+		first, err := b.allow("foobar.com")
+		require.NoError(t, err)
+		second, err := b.allow("foobar.com")
+		require.NoError(t, err)
+		_, err = b.allow("foobar.com")
+		assert.Error(t, err, "probes should be exhausted")
+
+		b.record("foobar.com", first, circuitSuccess)
+		b.record("foobar.com", second, circuitSuccess)
+		assert.Equal(t, circuitClosed, b.circuits["foobar.com"].state)
+		_, err = b.allow("foobar.com")
+		assert.NoError(t, err)
+	})
+
+	t.Run("HalfOpenReopens", func(t *testing.T) {
+		b, clock := newMockBreaker(policy)
+		b.allow("foobar.com")
+		b.setState(b.circuits["foobar.com"], circuitOpen, clock.now)
+
+		clock.now = clock.now.Add(10 * time.Second)
+		generation, err := b.allow("foobar.com")
+		require.NoError(t, err)
+		b.record("foobar.com", generation, circuitFailure)
+		assert.Equal(t, circuitOpen, b.circuits["foobar.com"].state)
+		_, err = b.allow("foobar.com")
+		assert.Error(t, err)
+	})
+
+	t.Run("HalfOpenIgnored", func(t *testing.T) {
+		b, clock := newMockBreaker(policy)
+		b.allow("foobar.com")
+		b.setState(b.circuits["foobar.com"], circuitOpen, clock.now)
+
+		clock.now = clock.now.Add(10 * time.Second)
+		generation, err := b.allow("foobar.com")
+		require.NoError(t, err)
+		_, err = b.allow("foobar.com")
+		require.NoError(t, err)
+		b.record("foobar.com", generation, circuitIgnored)
+		_, err = b.allow("foobar.com")
+		assert.NoError(t, err, "ignored probe should be released")
+	})
+
+	t.Run("StaleResult", func(t *testing.T) {
+		b, clock := newMockBreaker(policy)
+		stale, err := b.allow("foobar.com")
+		require.NoError(t, err)
+		b.setState(b.circuits["foobar.com"], circuitOpen, clock.now)
+
+		clock.now = clock.now.Add(10 * time.Second)
+		probe, err := b.allow("foobar.com")
+		require.NoError(t, err)
+
+		// Results of requests let through the closed circuit neither count
+		// as probes nor release them.
+		b.record("foobar.com", stale, circuitSuccess)
+		b.record("foobar.com", stale, circuitSuccess)
+		b.record("foobar.com", stale, circuitIgnored)
+		assert.Equal(t, circuitHalfOpen, b.circuits["foobar.com"].state)
+		assert.Equal(t, 1, b.circuits["foobar.com"].probes)
+		b.record("foobar.com", stale, circuitFailure)
+		assert.Equal(t, circuitHalfOpen, b.circuits["foobar.com"].state)
+
+		b.record("foobar.com", probe, circuitFailure)
+		assert.Equal(t, circuitOpen, b.circuits["foobar.com"].state)
+	})
+}
+
+func TestDoAttemptCircuitBreaker(t *testing.T) {
+	var attempts int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer s.Close()
+
+	c := &client{builder: &builder{
+		baseURL:        s.URL,
+		circuitBreaker: &CircuitBreakerPolicy{MinRequests: 2, OpenDuration: time.Hour},
+		retryPolicy:    &RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond, StatusCodes: []int{500}},
+	}}
+	response, err := c.doRequest(context.Background(), http.MethodGet, "/api", nil)
+	var circuitErr *ErrCircuitOpen
+	assert.True(t, errors.As(err, &circuitErr))
+	assert.Empty(t, response, "response should be nil")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+	assert.NotNil(t, c.getCircuitBreaker())
+}
+
+func TestGetCircuitBreaker(t *testing.T) {
+	c := &client{builder: &builder{}}
+	assert.Nil(t, c.getCircuitBreaker())
+}
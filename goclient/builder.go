@@ -8,11 +8,24 @@ import (
 // Builder provides the interface for custom HTTP implementations.
 type Builder interface {
 	Build() Client
+	BuildE() (Client, error)
+	Validate() error
 	SetBaseURL(baseUrl string) Builder
 	SetRequestHeaders(headers http.Header) Builder
 	SetConnectionTimeout(timeout time.Duration) Builder
 	SetResponseTimeout(timeout time.Duration) Builder
 	SetUserAgent(name string) Builder
+	SetRetryPolicy(policy RetryPolicy) Builder
+	SetCircuitBreaker(policy CircuitBreakerPolicy) Builder
+	SetRateLimit(limit RateLimit) Builder
+	Use(middleware ...Middleware) Builder
+	SetStatusErrors(enabled bool) Builder
+	SetMaxResponseBodySize(size int64) Builder
+	SetHeaderMergePolicy(policy HeaderMergePolicy) Builder
+	RegisterCodec(codec Codec) Builder
+	SetRequestCompression(compression Compression) Builder
+	SetTLSConfig(config TLSConfig) Builder
+	SetCertificatePins(pins CertificatePins) Builder
 }
 
 // builder provides configuration options for custom HTTP implementations.
@@ -23,6 +36,17 @@ type builder struct {
 	responseTimeout     time.Duration
 	connectionTimeout   time.Duration
 	maxIdleConnsPerHost int
+	retryPolicy         *RetryPolicy
+	circuitBreaker      *CircuitBreakerPolicy
+	rateLimit           *RateLimit
+	middleware          []Middleware
+	statusErrors        bool
+	maxResponseBodySize int64
+	headerMergePolicy   HeaderMergePolicy
+	codecs              map[string]Codec
+	compression         *Compression
+	tlsConfig           *TLSConfig
+	pins                *CertificatePins
 }
 
 // NewBuild provides a custom HTTP builder implementation.
@@ -31,14 +55,25 @@ func NewBuild() Builder {
 }
 
 // Build provides a custom HTTP client implementation with the desired config.
+// The base URL is parsed and the TLS files are loaded once, here, and an error
+// with either is returned by every request.
 func (b *builder) Build() Client {
-	return &client{builder: b}
+	c := &client{builder: b}
+	// An invalid base URL is not reported here. Its error is stored and
+	// returned by every request made with the client.
+	_, _ = c.getBaseURL()
+	// Likewise, an error loading the TLS files is stored and returned by every
+	// request.
+	_, _ = c.getTLSConfig()
+	return c
 }
 
-// SetBaseURL sets the base URL of a web service. If specified, it is
-// concatenated with the endpoint parameter value for every request. Also, the
-// endpoint parameter only requires the API resource. Otherwise, the absolute
-// URI must be supplied.
+// SetBaseURL sets the base URL of a web service. If specified, endpoints are
+// resolved against it as RFC 3986 references, with its path treated as a
+// directory, so the endpoint parameter only requires the API resource. A base
+// URL with a path prefix such as https://foobar.com/v2 is kept, and a leading
+// slash in the endpoint is relative to it. An absolute endpoint URL overrides
+// the base URL. Otherwise, the absolute URI must be supplied.
 func (b *builder) SetBaseURL(baseUrl string) Builder {
 	b.baseURL = baseUrl
 	return b
@@ -76,3 +111,93 @@ func (b *builder) SetUserAgent(name string) Builder {
 	b.userAgent = name
 	return b
 }
+
+// SetRetryPolicy sets the policy used to retry failed requests. By default,
+// each request is attempted exactly once.
+func (b *builder) SetRetryPolicy(policy RetryPolicy) Builder {
+	b.retryPolicy = &policy
+	return b
+}
+
+// SetCircuitBreaker enables a circuit breaker for each host. Requests to a host
+// whose circuit is open fail fast with an ErrCircuitOpen error.
+func (b *builder) SetCircuitBreaker(policy CircuitBreakerPolicy) Builder {
+	b.circuitBreaker = &policy
+	return b
+}
+
+// SetRateLimit sets a client-side rate limit. Requests that exceed it either
+// wait for a token or fail with ErrRateLimited.
+func (b *builder) SetRateLimit(limit RateLimit) Builder {
+	b.rateLimit = &limit
+	return b
+}
+
+// Use appends middleware to the chain wrapped around each request attempt.
+// Middleware is called in the order it is added, so the first one sees the
+// outgoing request first and the resulting response or error last.
+func (b *builder) Use(middleware ...Middleware) Builder {
+	b.middleware = append(b.middleware, middleware...)
+	return b
+}
+
+// SetStatusErrors sets whether responses with a non-success status code are
+// returned with a HTTPError. By default, only failed requests return an error
+// and the status code must be checked by the calling application.
+func (b *builder) SetStatusErrors(enabled bool) Builder {
+	b.statusErrors = enabled
+	return b
+}
+
+// SetMaxResponseBodySize sets the max size of a response body in bytes. Reading
+// stops once it is exceeded and an ErrResponseTooLarge error is returned. A
+// value of zero or less means no limit.
+func (b *builder) SetMaxResponseBodySize(size int64) Builder {
+	b.maxResponseBodySize = size
+	return b
+}
+
+// SetHeaderMergePolicy sets how request headers defined as part of a client
+// request are merged with the ones defined as part of the client build. By
+// default, request headers replace build headers of the same name.
+func (b *builder) SetHeaderMergePolicy(policy HeaderMergePolicy) Builder {
+	b.headerMergePolicy = policy
+	return b
+}
+
+// RegisterCodec registers a codec for each of its content types. Request
+// bodies are encoded by the codec of their Content-Type header and responses
+// are decoded by the codec of theirs. A registered codec replaces a built-in
+// or previously registered one for the same content type.
+func (b *builder) RegisterCodec(codec Codec) Builder {
+	if b.codecs == nil {
+		b.codecs = make(map[string]Codec)
+	}
+	for contentType, c := range newCodecMap(codec) {
+		b.codecs[contentType] = c
+	}
+	return b
+}
+
+// SetRequestCompression sets how request bodies are compressed. By default,
+// request bodies are sent uncompressed.
+func (b *builder) SetRequestCompression(compression Compression) Builder {
+	b.compression = &compression
+	return b
+}
+
+// SetTLSConfig sets the TLS settings of connections to web services, such as
+// the CA certificates used to verify them and the client certificate sent for
+// mutual TLS. By default, the settings of the standard library are used.
+func (b *builder) SetTLSConfig(config TLSConfig) Builder {
+	b.tlsConfig = &config
+	return b
+}
+
+// SetCertificatePins sets the public keys that web services must present, per
+// host, in addition to the verification of their certificate chain. By
+// default, no pins are checked.
+func (b *builder) SetCertificatePins(pins CertificatePins) Builder {
+	b.pins = &pins
+	return b
+}
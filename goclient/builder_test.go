@@ -16,6 +16,16 @@ func TestNewBuild(t *testing.T) {
 func TestBuild(t *testing.T) {
 	c := NewBuild().Build()
 	assert.IsType(t, &client{}, c)
+
+	t.Run("ParsesBaseURL", func(t *testing.T) {
+		c := NewBuild().SetBaseURL("https://foobar.com/v2").Build().(*client)
+		assert.Equal(t, "https://foobar.com/v2/", c.baseURL.String())
+	})
+	t.Run("InvalidBaseURL", func(t *testing.T) {
+		c := NewBuild().SetBaseURL("foobar.com").Build().(*client)
+		assert.Nil(t, c.baseURL)
+		assert.Error(t, c.baseURLErr)
+	})
 }
 
 func TestSetBaseURL(t *testing.T) {
@@ -81,3 +91,82 @@ func TestSetUserAgent(t *testing.T) {
 	assert.Equal(t, "go-http", b.userAgent)
 	assert.IsType(t, &builder{}, have)
 }
+
+func TestSetRetryPolicy(t *testing.T) {
+	b := &builder{}
+	have := b.SetRetryPolicy(RetryPolicy{MaxAttempts: 3})
+	assert.Equal(t, 3, b.retryPolicy.MaxAttempts)
+	assert.IsType(t, &builder{}, have)
+}
+
+func TestSetCircuitBreaker(t *testing.T) {
+	b := &builder{}
+	have := b.SetCircuitBreaker(CircuitBreakerPolicy{MinRequests: 5})
+	assert.Equal(t, 5, b.circuitBreaker.MinRequests)
+	assert.IsType(t, &builder{}, have)
+}
+
+func TestSetRateLimit(t *testing.T) {
+	b := &builder{}
+	have := b.SetRateLimit(RateLimit{Rate: 10, Burst: 5})
+	assert.Equal(t, 10.0, b.rateLimit.Rate)
+	assert.Equal(t, 5, b.rateLimit.Burst)
+	assert.IsType(t, &builder{}, have)
+}
+
+func TestUse(t *testing.T) {
+	b := &builder{}
+	noop := func(next RoundTripFunc) RoundTripFunc { return next }
+	have := b.Use(noop, noop)
+	assert.Len(t, b.middleware, 2)
+	assert.IsType(t, &builder{}, have)
+}
+
+func TestSetStatusErrors(t *testing.T) {
+	b := &builder{}
+	have := b.SetStatusErrors(true)
+	assert.True(t, b.statusErrors)
+	assert.IsType(t, &builder{}, have)
+}
+
+func TestSetMaxResponseBodySize(t *testing.T) {
+	b := &builder{}
+	have := b.SetMaxResponseBodySize(1 << 20)
+	assert.Equal(t, int64(1<<20), b.maxResponseBodySize)
+	assert.IsType(t, &builder{}, have)
+}
+
+func TestSetHeaderMergePolicy(t *testing.T) {
+	b := &builder{}
+	have := b.SetHeaderMergePolicy(HeaderAppend)
+	assert.Equal(t, HeaderAppend, b.headerMergePolicy)
+	assert.IsType(t, &builder{}, have)
+}
+
+func TestRegisterCodec(t *testing.T) {
+	b := &builder{}
+	have := b.RegisterCodec(mockCodec{})
+	assert.Equal(t, mockCodec{}, b.codecs["text/csv"])
+	assert.IsType(t, &builder{}, have)
+}
+
+func TestSetRequestCompression(t *testing.T) {
+	b := &builder{}
+	have := b.SetRequestCompression(Compression{Encoding: EncodingGzip, MinSize: 1024})
+	assert.Equal(t, &Compression{Encoding: EncodingGzip, MinSize: 1024}, b.compression)
+	assert.IsType(t, &builder{}, have)
+}
+
+func TestSetTLSConfig(t *testing.T) {
+	b := &builder{}
+	have := b.SetTLSConfig(TLSConfig{ServerName: "foobar.com"})
+	assert.Equal(t, &TLSConfig{ServerName: "foobar.com"}, b.tlsConfig)
+	assert.IsType(t, &builder{}, have)
+}
+
+func TestSetCertificatePins(t *testing.T) {
+	b := &builder{}
+	have := b.SetCertificatePins(CertificatePins{ReportOnly: true})
+	assert.Equal(t, &CertificatePins{ReportOnly: true}, b.pins)
+	assert.IsType(t, &builder{}, have)
+}
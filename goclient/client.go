@@ -1,15 +1,28 @@
 package goclient
 
 import (
+	"context"
+	"crypto/tls"
 	"net/http"
+	"net/url"
 	"sync"
 )
 
 // client provides the implementation of a custom HTTP client.
 type client struct {
-	builder  *builder
-	client   *http.Client
-	initOnce sync.Once
+	builder     *builder
+	client      *http.Client
+	initOnce    sync.Once
+	breaker     *circuitBreaker
+	breakerOnce sync.Once
+	limiter     *rateLimiter
+	limiterOnce sync.Once
+	baseURL     *url.URL
+	baseURLErr  error
+	baseURLOnce sync.Once
+	tlsConfig   *tls.Config
+	tlsErr      error
+	tlsOnce     sync.Once
 }
 
 // Client provides the interface for a custom HTTP client.
@@ -19,29 +32,73 @@ type Client interface {
 	Post(endpoint string, body any, headers ...http.Header) (*Response, error)
 	Patch(endpoint string, body any, headers ...http.Header) (*Response, error)
 	Delete(endpoint string, headers ...http.Header) (*Response, error)
+
+	GetContext(ctx context.Context, endpoint string, headers ...http.Header) (*Response, error)
+	PutContext(ctx context.Context, endpoint string, body any, headers ...http.Header) (*Response, error)
+	PostContext(ctx context.Context, endpoint string, body any, headers ...http.Header) (*Response, error)
+	PatchContext(ctx context.Context, endpoint string, body any, headers ...http.Header) (*Response, error)
+	DeleteContext(ctx context.Context, endpoint string, headers ...http.Header) (*Response, error)
+
+	Do(ctx context.Context, method, endpoint string, body any, options ...RequestOption) (*Response, error)
 }
 
 // Get issues a GET request to the specified URL.
 func (c *client) Get(endpoint string, headers ...http.Header) (*Response, error) {
-	return c.doRequest(http.MethodGet, endpoint, getRequestHeaders(headers...), nil)
+	return c.doRequest(context.Background(), http.MethodGet, endpoint, nil, WithHeaders(getRequestHeaders(headers...)))
 }
 
 // Put issues a PUT request to the specified URL.
 func (c *client) Put(endpoint string, body any, headers ...http.Header) (*Response, error) {
-	return c.doRequest(http.MethodPut, endpoint, getRequestHeaders(headers...), body)
+	return c.doRequest(context.Background(), http.MethodPut, endpoint, body, WithHeaders(getRequestHeaders(headers...)))
 }
 
 // Post issues a POST request to the specified URL.
 func (c *client) Post(endpoint string, body any, headers ...http.Header) (*Response, error) {
-	return c.doRequest(http.MethodPost, endpoint, getRequestHeaders(headers...), body)
+	return c.doRequest(context.Background(), http.MethodPost, endpoint, body, WithHeaders(getRequestHeaders(headers...)))
 }
 
 // Patch issues a PATCH request to the specified URL.
 func (c *client) Patch(endpoint string, body any, headers ...http.Header) (*Response, error) {
-	return c.doRequest(http.MethodPatch, endpoint, getRequestHeaders(headers...), body)
+	return c.doRequest(context.Background(), http.MethodPatch, endpoint, body, WithHeaders(getRequestHeaders(headers...)))
 }
 
 // Delete issues a DELETE request to the specified URL.
 func (c *client) Delete(endpoint string, headers ...http.Header) (*Response, error) {
-	return c.doRequest(http.MethodDelete, endpoint, getRequestHeaders(headers...), nil)
+	return c.doRequest(context.Background(), http.MethodDelete, endpoint, nil, WithHeaders(getRequestHeaders(headers...)))
+}
+
+// GetContext issues a GET request to the specified URL. The request is
+// cancelled when ctx is done.
+func (c *client) GetContext(ctx context.Context, endpoint string, headers ...http.Header) (*Response, error) {
+	return c.doRequest(ctx, http.MethodGet, endpoint, nil, WithHeaders(getRequestHeaders(headers...)))
+}
+
+// PutContext issues a PUT request to the specified URL. The request is
+// cancelled when ctx is done.
+func (c *client) PutContext(ctx context.Context, endpoint string, body any, headers ...http.Header) (*Response, error) {
+	return c.doRequest(ctx, http.MethodPut, endpoint, body, WithHeaders(getRequestHeaders(headers...)))
+}
+
+// PostContext issues a POST request to the specified URL. The request is
+// cancelled when ctx is done.
+func (c *client) PostContext(ctx context.Context, endpoint string, body any, headers ...http.Header) (*Response, error) {
+	return c.doRequest(ctx, http.MethodPost, endpoint, body, WithHeaders(getRequestHeaders(headers...)))
+}
+
+// PatchContext issues a PATCH request to the specified URL. The request is
+// cancelled when ctx is done.
+func (c *client) PatchContext(ctx context.Context, endpoint string, body any, headers ...http.Header) (*Response, error) {
+	return c.doRequest(ctx, http.MethodPatch, endpoint, body, WithHeaders(getRequestHeaders(headers...)))
+}
+
+// DeleteContext issues a DELETE request to the specified URL. The request is
+// cancelled when ctx is done.
+func (c *client) DeleteContext(ctx context.Context, endpoint string, headers ...http.Header) (*Response, error) {
+	return c.doRequest(ctx, http.MethodDelete, endpoint, nil, WithHeaders(getRequestHeaders(headers...)))
+}
+
+// Do issues a request of any method to the specified URL. Unlike the other
+// methods, it accepts request options such as WithStream.
+func (c *client) Do(ctx context.Context, method, endpoint string, body any, options ...RequestOption) (*Response, error) {
+	return c.doRequest(ctx, method, endpoint, body, options...)
 }
@@ -2,12 +2,15 @@ package goclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
 	"io"
+	"math"
+	"mime/multipart"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 )
@@ -18,15 +21,54 @@ const (
 	defaultMaxIdleConnsPerHost = 2
 )
 
-// getBaseURL returns the base URL of a service or an empty string.
-func (c *client) getBaseURL() (string, error) {
-	if c.builder.baseURL != "" {
-		if _, err := url.ParseRequestURI(c.builder.baseURL); err != nil {
-			return "", err
+// getBaseURL returns the parsed base URL of a service or nil. It is parsed
+// once, when the client is built, and its path is given a trailing slash so
+// that endpoints are resolved beneath it.
+func (c *client) getBaseURL() (*url.URL, error) {
+	c.baseURLOnce.Do(func() {
+		if c.builder.baseURL == "" {
+			return
 		}
-		return c.builder.baseURL, nil
+		u, err := url.ParseRequestURI(c.builder.baseURL)
+		if err != nil {
+			c.baseURLErr = err
+			return
+		}
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+			if u.RawPath != "" {
+				u.RawPath += "/"
+			}
+		}
+		c.baseURL = u
+	})
+	return c.baseURL, c.baseURLErr
+}
+
+// resolveURL returns the URL of a request to endpoint, resolved against base
+// as defined by RFC 3986. An absolute endpoint URL, or any endpoint if base is
+// nil, is returned unchanged. Otherwise, leading slashes are removed from the
+// endpoint so that it is resolved beneath the path of base rather than its
+// root.
+func resolveURL(base *url.URL, endpoint string) (string, error) {
+	ref, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if base == nil || ref.IsAbs() {
+		return endpoint, nil
 	}
-	return "", nil
+
+	// A colon in the first segment of a relative path would be parsed as a
+	// scheme, so the path is made explicitly relative.
+	relative := strings.TrimLeft(endpoint, "/")
+	if i := strings.IndexAny(relative, ":/?#"); i >= 0 && relative[i] == ':' {
+		relative = "./" + relative
+	}
+	if ref, err = url.Parse(relative); err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
 }
 
 // getConnectionTimeout returns the desired or default connection timeout.
@@ -54,35 +96,156 @@ func (c *client) getResponseTimeout() time.Duration {
 	return defaultResponseTimeout
 }
 
-// getRequestBody returns the content type encoding of the request body.
+// getMaxResponseBodySize returns the max size of a response body in bytes or
+// zero if there is no limit. The client request takes precedence over the
+// client build. A limit of math.MaxInt64 means no limit, since the body is
+// read up to one byte past the limit.
+func (c *client) getMaxResponseBodySize(opts *requestOptions) int64 {
+	size := c.builder.maxResponseBodySize
+	if opts.maxResponseBodySize != 0 {
+		size = opts.maxResponseBodySize
+	}
+	if size > 0 && size < math.MaxInt64 {
+		return size
+	}
+	return 0
+}
+
+// getRequestBody returns the content type encoding of the request body. The
+// codec is chosen by the media type of the Content-Type header, and JSON is
+// used if there is no codec for it. Bodies that are already encoded, such as
+// []byte, string and json.RawMessage, are passed through unchanged.
 func (c *client) getRequestBody(contentType string, body any) ([]byte, error) {
-	if body == nil {
+	switch v := body.(type) {
+	case nil:
 		return nil, nil
+	case []byte:
+		return v, nil
+	case json.RawMessage:
+		return v, nil
+	case string:
+		return []byte(v), nil
 	}
-	// TODO: Add support for other content types.
-	switch strings.ToLower(contentType) {
-	case "application/json":
-		b, err := json.Marshal(body)
-		return b, err
-	default:
-		b, err := json.Marshal(body)
-		return b, err
+	codec := findCodec(c.builder.codecs, contentType)
+	if codec == nil {
+		codec = jsonCodec{}
+	}
+	return codec.Marshal(body)
+}
+
+// bodyReader is the encoded body of a client request.
+type bodyReader struct {
+	reader  io.Reader
+	headers http.Header
+
+	// data holds the encoded body if it is fully buffered, which allows it
+	// to be compressed.
+	data []byte
+
+	// uncompressed is the original body of a compressed body.
+	uncompressed *bodyReader
+
+	// getBody returns a fresh copy of the body for retries. If nil, the
+	// standard library sets it for the reader types it knows.
+	getBody func() (io.ReadCloser, error)
+
+	// contentLength is the size of the body in bytes. If zero, the standard
+	// library sets it for the reader types it knows.
+	contentLength int64
+}
+
+// getRequestReader returns the encoded request body as a reader. Request body
+// types with their own encoding, such as Multipart, also set the Content-Type
+// header. An io.Reader is streamed without being encoded or closed. Otherwise,
+// the body is encoded according to the Content-Type header.
+func (c *client) getRequestReader(headers http.Header, body any) (*bodyReader, error) {
+	switch v := body.(type) {
+	case Multipart:
+		return c.getRequestReader(headers, &v)
+	case *Multipart:
+		mb := newMultipartBody(v, multipart.NewWriter(io.Discard).Boundary())
+		headers.Set(HeaderContentType, mb.contentType())
+		if mb.replayable() {
+			return &bodyReader{reader: mb, headers: headers, getBody: mb.getBody}, nil
+		}
+		return &bodyReader{reader: mb, headers: headers}, nil
+	case *bytes.Buffer, *bytes.Reader, *strings.Reader:
+		return &bodyReader{reader: v.(io.Reader), headers: headers}, nil
+	case io.Reader:
+		br := getStreamReader(v)
+		br.headers = headers
+		return br, nil
+	}
+
+	requestBody, err := c.getRequestBody(headers.Get(HeaderContentType), body)
+	if err != nil {
+		return nil, err
+	}
+	return &bodyReader{reader: bytes.NewBuffer(requestBody), headers: headers, data: requestBody}, nil
+}
+
+// newRequest returns a request with the given body and its headers.
+func newRequest(ctx context.Context, method, requestURL string, body *bodyReader) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, body.reader)
+	if err != nil {
+		return nil, err
+	}
+	request.Header = body.headers
+	if body.getBody != nil {
+		request.GetBody = body.getBody
+	}
+	if body.contentLength > 0 {
+		request.ContentLength = body.contentLength
+	}
+	return request, nil
+}
+
+// getStreamReader returns a reader that streams r as the request body. The
+// size of a regular file is sent as Content-Length, and a reader that can seek
+// is replayed from its current offset for retries.
+func getStreamReader(r io.Reader) *bodyReader {
+	br := &bodyReader{reader: io.NopCloser(r)}
+	if f, ok := r.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+			if offset, err := f.Seek(0, io.SeekCurrent); err == nil {
+				br.contentLength = info.Size() - offset
+			}
+		}
+	}
+	if seeker, ok := r.(io.Seeker); ok {
+		if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			br.getBody = func() (io.ReadCloser, error) {
+				if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+					return nil, err
+				}
+				return io.NopCloser(r), nil
+			}
+		}
 	}
+	return br
 }
 
 // getClient returns a custom HTTP client with the desired configurations. It
 // is resuable making it concurrent safe with goroutines.
 func (c *client) getClient() *http.Client {
 	c.initOnce.Do(func() {
+		tlsConfig, _ := c.getTLSConfig()
+		dialer := &net.Dialer{Timeout: c.getConnectionTimeout()}
+		transport := &http.Transport{
+			TLSClientConfig:       tlsConfig,
+			MaxIdleConnsPerHost:   c.getMaxIdleConnsPerHost(),
+			ResponseHeaderTimeout: c.getResponseTimeout(),
+			DialContext:           dialer.DialContext,
+		}
+
+		// A custom verifier needs the host of the web service, which the
+		// standard library does not pass to it for IP addresses.
+		if tlsConfig != nil && tlsConfig.VerifyConnection != nil {
+			transport.DialTLSContext = newTLSDialer(tlsConfig, dialer)
+		}
 		c.client = &http.Client{
-			Timeout: c.getConnectionTimeout() + c.getResponseTimeout(),
-			Transport: &http.Transport{
-				MaxIdleConnsPerHost:   c.getMaxIdleConnsPerHost(),
-				ResponseHeaderTimeout: c.getResponseTimeout(),
-				DialContext: (&net.Dialer{
-					Timeout: c.getConnectionTimeout(),
-				}).DialContext,
-			},
+			Timeout:   c.getConnectionTimeout() + c.getResponseTimeout(),
+			Transport: transport,
 		}
 	})
 	return c.client
@@ -90,45 +253,203 @@ func (c *client) getClient() *http.Client {
 
 // doRequest calls Do from the standard library to perform HTTP requests. It
 // also handles the low-level plumbing such as building the request, using the
-// custom HTTP client, and returning the response.
-func (c *client) doRequest(method, endpoint string, headers http.Header, body any) (*Response, error) {
+// custom HTTP client, and returning the response. The request is bound to ctx,
+// so it is abandoned as soon as ctx is cancelled or its deadline is exceeded.
+func (c *client) doRequest(ctx context.Context, method, endpoint string, body any, options ...RequestOption) (*Response, error) {
+	opts := newRequestOptions(options...)
 	baseURL, err := c.getBaseURL()
 	if err != nil {
 		return nil, err
 	}
+	if _, err := c.getTLSConfig(); err != nil {
+		return nil, err
+	}
+
+	endpoint, err = expandPath(endpoint, opts.pathParams)
+	if err != nil {
+		return nil, err
+	}
 
-	// If baseURL is nil, it must be parsed to the endpoint parameter.
+	// If baseURL is nil, the endpoint parameter must be an absolute URL.
 	// Otherwise, an unsupported protocol scheme error will be thrown by the
 	// HTTP client when it attempts to perform the request.
-	requestURL := fmt.Sprintf(baseURL + endpoint)
-	requestHeaders := c.joinRequestHeaders(headers)
-	requestBody, err := c.getRequestBody(requestHeaders.Get(HeaderContentType), body)
+	requestURL, err := resolveURL(baseURL, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	requestURL, err = addQuery(requestURL, opts.query)
 	if err != nil {
 		return nil, err
 	}
+	requestHeaders := c.joinRequestHeaders(opts.headers, opts.removeHeaders...)
 
-	request, err := http.NewRequest(method, requestURL, bytes.NewBuffer(requestBody))
+	// The standard library only decodes gzip, and only if Accept-Encoding is
+	// not set, so the client advertises and decodes its own content encodings
+	// unless the header is set by the calling application.
+	if requestHeaders.Get(HeaderAcceptEncoding) == "" {
+		requestHeaders.Set(HeaderAcceptEncoding, acceptEncoding)
+		opts.decompress = true
+	}
+	requestBody, err := c.getRequestReader(requestHeaders, body)
 	if err != nil {
 		return nil, err
 	}
-	request.Header = requestHeaders
 
-	response, err := c.getClient().Do(request)
+	compressedBody, err := c.compressRequestBody(requestBody, opts)
 	if err != nil {
 		return nil, err
 	}
-	defer response.Body.Close()
+	if compressedBody != nil {
+		requestBody = compressedBody
+	}
+
+	request, err := newRequest(ctx, method, requestURL, requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.doWithRetry(request, opts)
+
+	// Some web services reject compressed request bodies, in which case the
+	// request is sent again uncompressed.
+	if compressedBody != nil && err == nil && response.StatusCode == http.StatusUnsupportedMediaType {
+		response.Close()
+		requestBody = compressedBody.uncompressed
+		if request, err = newRequest(ctx, method, requestURL, requestBody); err != nil {
+			return nil, err
+		}
+		response, err = c.doWithRetry(request, opts)
+	}
+	if err != nil || !c.builder.statusErrors {
+		return response, err
+	}
+	if httpErr := newHTTPError(request, response); httpErr != nil {
+		return response, httpErr
+	}
+	return response, nil
+}
 
-	responseBody, err := io.ReadAll(response.Body)
+// doAttempt performs a single attempt of a client request. The attempt first
+// takes a token if a rate limit is defined as part of the client build. If a
+// circuit breaker is defined, the attempt is rejected while the circuit of its
+// host is open and its result is recorded otherwise.
+func (c *client) doAttempt(request *http.Request, opts *requestOptions) (*Response, error) {
+	if err := c.waitRateLimit(request); err != nil {
+		return nil, err
+	}
+
+	breaker := c.getCircuitBreaker()
+	if breaker == nil {
+		return c.send(request, opts)
+	}
+
+	host := request.URL.Host
+	generation, err := breaker.allow(host)
 	if err != nil {
 		return nil, err
 	}
+	response, err := c.send(request, opts)
+	switch {
+	case err != nil && request.Context().Err() != nil:
+		breaker.record(host, generation, circuitIgnored)
+	case err != nil, response.StatusCode >= http.StatusInternalServerError:
+		breaker.record(host, generation, circuitFailure)
+	default:
+		breaker.record(host, generation, circuitSuccess)
+	}
+	return response, err
+}
+
+// send calls Do from the standard library, through the middleware chain, to
+// send the request. The response body is read in full and closed before the
+// response is returned, unless the response is streamed.
+func (c *client) send(request *http.Request, opts *requestOptions) (*Response, error) {
+	ctx := request.Context()
+	response, err := c.roundTrip(request, opts)
+	if err != nil {
+		return nil, wrapContextError(ctx, err)
+	}
 
 	responseData := Response{
-		Body:            responseBody,
 		Status:          response.Status,
 		StatusCode:      response.StatusCode,
 		ResponseHeaders: response.Header,
+		request:         request,
+		codecs:          c.builder.codecs,
+	}
+	responseData.RetryAfter, responseData.hasRetryAfter = getRetryAfter(response.StatusCode, response.Header)
+	// Responses without a body, such as those of HEAD requests or with a 304
+	// status code, may declare the length of a body they do not have.
+	limit := c.getMaxResponseBodySize(opts)
+	hasBody := request.Method != http.MethodHead && response.Body != http.NoBody
+	if limit > 0 && hasBody && response.ContentLength > limit {
+		response.Body.Close()
+		return nil, &ErrResponseTooLarge{Limit: limit, ContentLength: response.ContentLength}
+	}
+	if opts.decompress {
+		responseData.ContentEncoding = decodeResponse(response)
+	}
+
+	if opts.stream {
+		responseData.Stream = response.Body
+		if limit > 0 {
+			responseData.Stream = newLimitedBody(response.Body, limit, response.ContentLength)
+		}
+		return &responseData, nil
+	}
+	defer response.Body.Close()
+
+	responseData.Body, err = readBody(response.Body, limit, response.ContentLength)
+	if err != nil {
+		return nil, wrapContextError(ctx, err)
 	}
 	return &responseData, nil
 }
+
+// readBody reads the response body in full. If limit is greater than zero, an
+// ErrResponseTooLarge error is returned once more than limit bytes are read.
+func readBody(body io.Reader, limit, contentLength int64) ([]byte, error) {
+	if limit <= 0 || limit == math.MaxInt64 {
+		return io.ReadAll(body)
+	}
+	b, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > limit {
+		return nil, &ErrResponseTooLarge{Limit: limit, Read: int64(len(b)), ContentLength: contentLength}
+	}
+	return b, nil
+}
+
+// limitedBody is a streamed response body that fails with an
+// ErrResponseTooLarge error once more than limit bytes are read.
+type limitedBody struct {
+	io.ReadCloser
+	limit         int64
+	read          int64
+	contentLength int64
+}
+
+// newLimitedBody returns body wrapped with the given limit.
+func newLimitedBody(body io.ReadCloser, limit, contentLength int64) *limitedBody {
+	return &limitedBody{ReadCloser: body, limit: limit, contentLength: contentLength}
+}
+
+// Read reads from the response body up to one byte past the limit, which is
+// reported as an ErrResponseTooLarge error rather than returned.
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.read > l.limit {
+		return 0, &ErrResponseTooLarge{Limit: l.limit, Read: l.read, ContentLength: l.contentLength}
+	}
+	if remaining := l.limit + 1 - l.read; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := l.ReadCloser.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		n--
+		return n, &ErrResponseTooLarge{Limit: l.limit, Read: l.read, ContentLength: l.contentLength}
+	}
+	return n, err
+}
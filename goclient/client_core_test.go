@@ -1,8 +1,18 @@
 package goclient
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -10,6 +20,11 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+type mockForm struct {
+	GrantType string `form:"grant_type"`
+	Scope     string `form:"scope,omitempty"`
+}
+
 type mockCore struct {
 	A string `json:"A"`
 	B string `json:"B"`
@@ -25,7 +40,17 @@ func TestGetBaseURL(t *testing.T) {
 		{
 			name:   "HasURL",
 			build:  &builder{baseURL: "https://foobar.com"},
-			expect: "https://foobar.com",
+			expect: "https://foobar.com/",
+		},
+		{
+			name:   "PathPrefix",
+			build:  &builder{baseURL: "https://foobar.com/v2"},
+			expect: "https://foobar.com/v2/",
+		},
+		{
+			name:   "TrailingSlash",
+			build:  &builder{baseURL: "https://foobar.com/v2/"},
+			expect: "https://foobar.com/v2/",
 		},
 		{
 			name:   "NoURL",
@@ -45,15 +70,103 @@ func TestGetBaseURL(t *testing.T) {
 			have, err := c.getBaseURL()
 			if tc.hasError {
 				assert.Error(t, err)
-				assert.Empty(t, have, "body should be nil")
+				assert.Nil(t, have, "base URL should be nil")
 				return
 			}
-			assert.Equal(t, tc.expect, have)
+			if have == nil {
+				assert.Equal(t, tc.expect, "")
+			} else {
+				assert.Equal(t, tc.expect, have.String())
+			}
 			require.NoError(t, err, "expected no errors")
 		})
 	}
 }
 
+func TestResolveURL(t *testing.T) {
+	tt := []struct {
+		name     string
+		base     string
+		endpoint string
+		expect   string
+		hasError bool
+	}{
+		{
+			name:     "NoBaseURL",
+			endpoint: "https://foobar.com/students",
+			expect:   "https://foobar.com/students",
+		},
+		{
+			name:     "LeadingSlash",
+			base:     "https://foobar.com",
+			endpoint: "/students",
+			expect:   "https://foobar.com/students",
+		},
+		{
+			name:     "NoLeadingSlash",
+			base:     "https://foobar.com",
+			endpoint: "students",
+			expect:   "https://foobar.com/students",
+		},
+		{
+			name:     "PathPrefix",
+			base:     "https://foobar.com/v2",
+			endpoint: "/students?id=1",
+			expect:   "https://foobar.com/v2/students?id=1",
+		},
+		{
+			name:     "PathPrefixTrailingSlash",
+			base:     "https://foobar.com/v2/",
+			endpoint: "//students",
+			expect:   "https://foobar.com/v2/students",
+		},
+		{
+			name:     "PercentInEndpoint",
+			base:     "https://foobar.com",
+			endpoint: "/students/100%25",
+			expect:   "https://foobar.com/students/100%25",
+		},
+		{
+			name:     "ColonInEndpoint",
+			base:     "https://foobar.com/v2",
+			endpoint: "/students:search",
+			expect:   "https://foobar.com/v2/students:search",
+		},
+		{
+			name:     "DotSegments",
+			base:     "https://foobar.com/v2/",
+			endpoint: "../v1/students",
+			expect:   "https://foobar.com/v1/students",
+		},
+		{
+			name:     "AbsoluteEndpoint",
+			base:     "https://foobar.com/v2",
+			endpoint: "http://barfoo.com/students",
+			expect:   "http://barfoo.com/students",
+		},
+		{
+			name:     "InvalidEndpoint",
+			base:     "https://foobar.com",
+			endpoint: "/students/%zz",
+			hasError: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &client{builder: &builder{baseURL: tc.base}}
+			base, err := c.getBaseURL()
+			require.NoError(t, err)
+			have, err := resolveURL(base, tc.endpoint)
+			if tc.hasError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, have)
+		})
+	}
+}
+
 func TestGetConnectionTimeout(t *testing.T) {
 	tt := []struct {
 		name   string
@@ -129,6 +242,49 @@ func TestGetResponseTimeout(t *testing.T) {
 	}
 }
 
+func TestGetMaxResponseBodySize(t *testing.T) {
+	tt := []struct {
+		name    string
+		build   *builder
+		options []RequestOption
+		expect  int64
+	}{
+		{
+			name:   "NoLimit",
+			build:  &builder{},
+			expect: 0,
+		},
+		{
+			name:   "BuildLimit",
+			build:  &builder{maxResponseBodySize: 1024},
+			expect: 1024,
+		},
+		{
+			name:    "RequestLimit",
+			build:   &builder{maxResponseBodySize: 1024},
+			options: []RequestOption{WithMaxResponseBodySize(2048)},
+			expect:  2048,
+		},
+		{
+			name:    "RequestNoLimit",
+			build:   &builder{maxResponseBodySize: 1024},
+			options: []RequestOption{WithMaxResponseBodySize(-1)},
+			expect:  0,
+		},
+		{
+			name:   "MaxInt64",
+			build:  &builder{maxResponseBodySize: math.MaxInt64},
+			expect: 0,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &client{builder: tc.build}
+			assert.Equal(t, tc.expect, c.getMaxResponseBodySize(newRequestOptions(tc.options...)))
+		})
+	}
+}
+
 func TestGetRequestBody(t *testing.T) {
 	tt := []struct {
 		name        string
@@ -156,6 +312,68 @@ func TestGetRequestBody(t *testing.T) {
 			body:        &mockCore{A: "foo", B: "bar"},
 			expect:      []byte(`{"A":"foo","B":"bar"}`),
 		},
+		{
+			name:        "FormValues",
+			contentType: "application/x-www-form-urlencoded; charset=utf-8",
+			body:        url.Values{"grant_type": {"client_credentials"}, "scope": {"a b"}},
+			expect:      []byte(`grant_type=client_credentials&scope=a+b`),
+		},
+		{
+			name:        "FormStruct",
+			contentType: ContentTypeForm,
+			body:        &mockForm{GrantType: "client_credentials"},
+			expect:      []byte(`grant_type=client_credentials`),
+		},
+		{
+			name:        "FormError",
+			contentType: ContentTypeForm,
+			body:        []string{"foobar"},
+			hasError:    true,
+			expect:      nil,
+		},
+		{
+			name:        "ApplicationXml",
+			contentType: "application/xml; charset=utf-8",
+			body:        &mockXml{ID: 1, Name: "foobar"},
+			expect:      []byte(`<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<student id="1"><name>foobar</name></student>`),
+		},
+		{
+			name:        "TextXml",
+			contentType: ContentTypeTextXml,
+			body:        &mockXml{ID: 1, Name: "foobar"},
+			expect:      []byte(`<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<student id="1"><name>foobar</name></student>`),
+		},
+		{
+			name:        "SuffixJson",
+			contentType: "application/vnd.api+json",
+			body:        &mockCore{A: "foo", B: "bar"},
+			expect:      []byte(`{"A":"foo","B":"bar"}`),
+		},
+		{
+			name:        "RegisteredCodec",
+			build:       &builder{codecs: newCodecMap(mockCodec{})},
+			contentType: "text/csv",
+			body:        &mockCore{A: "foo", B: "bar"},
+			expect:      []byte("foo,bar"),
+		},
+		{
+			name:        "RawBytes",
+			contentType: ContentTypeJson,
+			body:        []byte(`{"A":"foo"}`),
+			expect:      []byte(`{"A":"foo"}`),
+		},
+		{
+			name:        "RawMessage",
+			contentType: ContentTypeJson,
+			body:        json.RawMessage(`{"A":"foo"}`),
+			expect:      []byte(`{"A":"foo"}`),
+		},
+		{
+			name:        "RawString",
+			contentType: "text/plain",
+			body:        "foobar",
+			expect:      []byte("foobar"),
+		},
 		{
 			name:        "MarshalError",
 			contentType: ContentTypeJson,
@@ -166,7 +384,11 @@ func TestGetRequestBody(t *testing.T) {
 	}
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
-			c := &client{}
+			build := tc.build
+			if build == nil {
+				build = &builder{}
+			}
+			c := &client{builder: build}
 			body, err := c.getRequestBody(tc.contentType, tc.body)
 			if tc.hasError {
 				assert.Error(t, err)
@@ -261,7 +483,7 @@ func TestDoRequest(t *testing.T) {
 			}
 
 			c := &client{builder: &builder{baseURL: tc.url}}
-			response, err := c.doRequest(tc.method, "/api", tc.headers, tc.body)
+			response, err := c.doRequest(context.Background(), tc.method, "/api", tc.body, WithHeaders(tc.headers))
 
 			if tc.hasError {
 				assert.Error(t, err)
@@ -278,3 +500,257 @@ func TestDoRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestDoRequestContext(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer s.Close()
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		c := &client{builder: &builder{baseURL: s.URL}}
+		response, err := c.doRequest(ctx, http.MethodGet, "/api", nil)
+		assert.Empty(t, response, "response should be nil")
+		assert.ErrorIs(t, err, ErrRequestCancelled)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("DeadlineExceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		c := &client{builder: &builder{baseURL: s.URL}}
+		response, err := c.doRequest(ctx, http.MethodGet, "/api", nil)
+		assert.Empty(t, response, "response should be nil")
+		assert.ErrorIs(t, err, ErrRequestCancelled)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("NotCancelled", func(t *testing.T) {
+		err := wrapContextError(context.Background(), errors.New("foobar"))
+		assert.NotErrorIs(t, err, ErrRequestCancelled)
+	})
+}
+
+func TestDoRequestStream(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		for i := 0; i < 5; i++ {
+			w.Write([]byte("foobar"))
+			w.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	defer s.Close()
+
+	// The overall timeout of the client is 100ms, which is shorter than the
+	// time taken to send the response body.
+	c := &client{builder: &builder{
+		baseURL:           s.URL,
+		connectionTimeout: 50 * time.Millisecond,
+		responseTimeout:   50 * time.Millisecond,
+	}}
+
+	t.Run("Buffered", func(t *testing.T) {
+		response, err := c.doRequest(context.Background(), http.MethodGet, "/api", nil)
+		assert.Error(t, err)
+		assert.Empty(t, response, "response should be nil")
+	})
+
+	t.Run("Streamed", func(t *testing.T) {
+		response, err := c.doRequest(context.Background(), http.MethodGet, "/api", nil, WithStream())
+		require.NoError(t, err, "expected no errors")
+		defer response.Close()
+		assert.Nil(t, response.Body)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		body, err := io.ReadAll(response.Stream)
+		require.NoError(t, err, "expected no errors")
+		assert.Equal(t, strings.Repeat("foobar", 5), string(body))
+	})
+}
+
+func TestDoRequestMaxResponseBodySize(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/not-modified" {
+			w.Header().Set(HeaderContentLength, "6")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if r.URL.Path == "/chunked" {
+			w.Write([]byte("foo"))
+			w.(http.Flusher).Flush()
+			w.Write([]byte("bar"))
+			return
+		}
+		w.Header().Set(HeaderContentLength, "6")
+		w.Write([]byte("foobar"))
+	}))
+	defer s.Close()
+
+	c := &client{builder: &builder{baseURL: s.URL, maxResponseBodySize: 4}}
+	tt := []struct {
+		name     string
+		method   string
+		endpoint string
+		options  []RequestOption
+		expect   *ErrResponseTooLarge
+		body     string
+	}{
+		{
+			name:     "DeclaredLength",
+			endpoint: "/api",
+			expect:   &ErrResponseTooLarge{Limit: 4, Read: 0, ContentLength: 6},
+		},
+		{
+			name:     "UnknownLength",
+			endpoint: "/chunked",
+			expect:   &ErrResponseTooLarge{Limit: 4, Read: 5, ContentLength: -1},
+		},
+		{
+			name:     "StreamedUnknownLength",
+			endpoint: "/chunked",
+			options:  []RequestOption{WithStream()},
+			expect:   &ErrResponseTooLarge{Limit: 4, Read: 5, ContentLength: -1},
+		},
+		{
+			name:     "WithinLimit",
+			endpoint: "/chunked",
+			options:  []RequestOption{WithMaxResponseBodySize(6)},
+			expect:   nil,
+			body:     "foobar",
+		},
+		{
+			name:     "MaxInt64",
+			endpoint: "/api",
+			options:  []RequestOption{WithMaxResponseBodySize(math.MaxInt64)},
+			expect:   nil,
+			body:     "foobar",
+		},
+		{
+			name:     "StreamedMaxInt64",
+			endpoint: "/chunked",
+			options:  []RequestOption{WithStream(), WithMaxResponseBodySize(math.MaxInt64)},
+			expect:   nil,
+			body:     "foobar",
+		},
+		{
+			name:     "Head",
+			method:   http.MethodHead,
+			endpoint: "/api",
+			expect:   nil,
+		},
+		{
+			name:     "NotModified",
+			endpoint: "/not-modified",
+			expect:   nil,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			response, err := c.doRequest(context.Background(), method, tc.endpoint, nil, tc.options...)
+			if response != nil && response.Stream != nil {
+				defer response.Close()
+				response.Body, err = io.ReadAll(response.Stream)
+				if tc.expect != nil {
+					assert.Equal(t, "foob", string(response.Body))
+				}
+			}
+
+			if tc.expect == nil {
+				require.NoError(t, err, "expected no errors")
+				assert.Equal(t, tc.body, response.StringBody())
+				return
+			}
+			var sizeErr *ErrResponseTooLarge
+			require.True(t, errors.As(err, &sizeErr))
+			assert.Equal(t, tc.expect, sizeErr)
+		})
+	}
+}
+
+func TestDoRequestReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "body.txt")
+	require.NoError(t, os.WriteFile(path, []byte("xxfoobar"), 0o600))
+
+	tt := []struct {
+		name          string
+		body          func(t *testing.T) io.Reader
+		contentLength int64
+		attempts      int32
+	}{
+		{
+			name: "StringsReader",
+			body: func(t *testing.T) io.Reader {
+				return strings.NewReader("foobar")
+			},
+			contentLength: 6,
+			attempts:      2,
+		},
+		{
+			name: "File",
+			body: func(t *testing.T) io.Reader {
+				f, err := os.Open(path)
+				require.NoError(t, err, "expected no errors")
+				t.Cleanup(func() { f.Close() })
+
+				_, err = f.Seek(2, io.SeekStart)
+				require.NoError(t, err, "expected no errors")
+				return f
+			},
+			contentLength: 6,
+			attempts:      2,
+		},
+		{
+			name: "UnknownLength",
+			body: func(t *testing.T) io.Reader {
+				return io.MultiReader(strings.NewReader("foo"), strings.NewReader("bar"))
+			},
+			contentLength: -1,
+			attempts:      1,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts int32
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tc.contentLength, r.ContentLength)
+				requestBody, err := io.ReadAll(r.Body)
+				require.NoError(t, err, "expected no errors")
+				assert.Equal(t, "foobar", string(requestBody))
+
+				if atomic.AddInt32(&attempts, 1) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer s.Close()
+
+			c := &client{builder: &builder{
+				baseURL:     s.URL,
+				retryPolicy: &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+			}}
+			body := tc.body(t)
+			_, err := c.doRequest(context.Background(), http.MethodPut, "/api", body)
+			require.NoError(t, err, "expected no errors")
+			assert.Equal(t, tc.attempts, atomic.LoadInt32(&attempts))
+
+			// The reader of the calling application is never closed.
+			if f, ok := body.(*os.File); ok {
+				_, err := f.Stat()
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
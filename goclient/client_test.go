@@ -1,6 +1,7 @@
 package goclient
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -152,3 +153,95 @@ func TestDelete(t *testing.T) {
 		require.NoError(t, err, "expected no errors")
 	})
 }
+
+func TestContextMethods(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Method", r.Method)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	c := NewClient()
+	tt := []struct {
+		name   string
+		method string
+		call   func(ctx context.Context) (*Response, error)
+	}{
+		{
+			name:   "GetContext",
+			method: http.MethodGet,
+			call: func(ctx context.Context) (*Response, error) {
+				return c.GetContext(ctx, s.URL)
+			},
+		},
+		{
+			name:   "PutContext",
+			method: http.MethodPut,
+			call: func(ctx context.Context) (*Response, error) {
+				return c.PutContext(ctx, s.URL, mockClient{Name: "foobar"})
+			},
+		},
+		{
+			name:   "PostContext",
+			method: http.MethodPost,
+			call: func(ctx context.Context) (*Response, error) {
+				return c.PostContext(ctx, s.URL, mockClient{Name: "foobar"})
+			},
+		},
+		{
+			name:   "PatchContext",
+			method: http.MethodPatch,
+			call: func(ctx context.Context) (*Response, error) {
+				return c.PatchContext(ctx, s.URL, mockClient{Name: "foobar"})
+			},
+		},
+		{
+			name:   "DeleteContext",
+			method: http.MethodDelete,
+			call: func(ctx context.Context) (*Response, error) {
+				return c.DeleteContext(ctx, s.URL)
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			response, err := tc.call(context.Background())
+			require.NoError(t, err, "expected no errors")
+			assert.Equal(t, tc.method, response.ResponseHeaders.Get("X-Method"))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			response, err = tc.call(ctx)
+			assert.ErrorIs(t, err, ErrRequestCancelled)
+			assert.Empty(t, response, "response should be nil")
+		})
+	}
+}
+
+func TestDo(t *testing.T) {
+	t.Run("SuccessfulDo", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "foobar", r.Header.Get("X-Request"))
+
+			requestBody, err := io.ReadAll(r.Body)
+			assert.Equal(t, `{"Name":"foobar"}`, string(requestBody))
+			require.NoError(t, err, "expected no errors")
+
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{ "Response": "OK" }`))
+		}))
+		defer s.Close()
+
+		c := NewClient()
+
+		headers := http.Header{"X-Request": {"foobar"}}
+		response, err := c.Do(context.Background(), http.MethodPost, s.URL, mockClient{Name: "foobar"}, WithHeaders(headers))
+		require.NoError(t, err, "expected no errors")
+		var jsonData mockClient
+		err = response.UnmarshalJson(&jsonData)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "OK", jsonData.Response)
+		require.NoError(t, err, "expected no errors")
+	})
+}
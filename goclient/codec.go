@@ -0,0 +1,128 @@
+package goclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrNoCodec is returned when a body cannot be decoded because no codec is
+// registered for its content type.
+var ErrNoCodec = errors.New("goclient: no codec registered for content type")
+
+// Codec encodes and decodes bodies of the content types it supports. Custom
+// codecs, such as msgpack, CBOR or protobuf, can be registered as part of a
+// client build.
+type Codec interface {
+	// ContentTypes returns the media types supported by the codec, such as
+	// application/json, without any parameters.
+	ContentTypes() []string
+
+	// Marshal returns the encoding of v.
+	Marshal(v any) ([]byte, error)
+
+	// Unmarshal decodes data into the value pointed to by v.
+	Unmarshal(data []byte, v any) error
+}
+
+// defaultCodecs are the built-in codecs, keyed by media type. Codecs
+// registered as part of a client build take precedence.
+var defaultCodecs = newCodecMap(jsonCodec{}, xmlCodec{}, formCodec{})
+
+// newCodecMap returns the codecs keyed by each of their media types.
+func newCodecMap(codecs ...Codec) map[string]Codec {
+	m := make(map[string]Codec)
+	for _, codec := range codecs {
+		for _, contentType := range codec.ContentTypes() {
+			m[getMediaType(contentType)] = codec
+		}
+	}
+	return m
+}
+
+// findCodec returns the codec of a Content-Type header value from the given
+// codecs or the built-in ones. Media types with a +json or +xml suffix fall
+// back to the JSON or XML codec. Nil is returned if no codec is found.
+func findCodec(codecs map[string]Codec, contentType string) Codec {
+	mediaType := getMediaType(contentType)
+	if codec, ok := codecs[mediaType]; ok {
+		return codec
+	}
+	if codec, ok := defaultCodecs[mediaType]; ok {
+		return codec
+	}
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return findCodec(codecs, ContentTypeJson)
+	case strings.HasSuffix(mediaType, "+xml"):
+		return findCodec(codecs, ContentTypeXml)
+	}
+	return nil
+}
+
+// jsonCodec encodes and decodes JSON using the json package from the standard
+// library.
+type jsonCodec struct{}
+
+func (jsonCodec) ContentTypes() []string {
+	return []string{ContentTypeJson, ContentTypeProblemJson}
+}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// xmlCodec encodes and decodes XML using the xml package from the standard
+// library.
+type xmlCodec struct{}
+
+func (xmlCodec) ContentTypes() []string {
+	return []string{ContentTypeXml, ContentTypeTextXml}
+}
+
+func (xmlCodec) Marshal(v any) ([]byte, error) {
+	return encodeXml(v)
+}
+
+func (xmlCodec) Unmarshal(data []byte, v any) error {
+	return decodeXml(data, v)
+}
+
+// formCodec encodes and decodes application/x-www-form-urlencoded bodies.
+type formCodec struct{}
+
+func (formCodec) ContentTypes() []string {
+	return []string{ContentTypeForm}
+}
+
+func (formCodec) Marshal(v any) ([]byte, error) {
+	return encodeForm(v)
+}
+
+// Unmarshal decodes form data into a url.Values or a map of strings.
+func (formCodec) Unmarshal(data []byte, v any) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	switch target := v.(type) {
+	case *url.Values:
+		*target = values
+	case *map[string][]string:
+		*target = values
+	case *map[string]string:
+		*target = make(map[string]string, len(values))
+		for key := range values {
+			(*target)[key] = values.Get(key)
+		}
+	default:
+		return fmt.Errorf("goclient: cannot decode form data into %T", v)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package goclient
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockCodec encodes and decodes a mockCore as a single line of CSV.
+type mockCodec struct{}
+
+func (mockCodec) ContentTypes() []string {
+	return []string{"text/csv"}
+}
+
+func (mockCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(*mockCore)
+	if !ok {
+		return nil, errors.New("unsupported type")
+	}
+	return []byte(m.A + "," + m.B), nil
+}
+
+func (mockCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(*mockCore)
+	if !ok {
+		return errors.New("unsupported type")
+	}
+	m.A, m.B, _ = strings.Cut(string(data), ",")
+	return nil
+}
+
+// mockJsonCodec replaces the built-in JSON codec.
+type mockJsonCodec struct {
+	jsonCodec
+}
+
+func TestFindCodec(t *testing.T) {
+	registered := newCodecMap(mockCodec{}, mockJsonCodec{})
+	tt := []struct {
+		name        string
+		codecs      map[string]Codec
+		contentType string
+		expect      Codec
+	}{
+		{
+			name:        "Json",
+			contentType: "application/json; charset=utf-8",
+			expect:      jsonCodec{},
+		},
+		{
+			name:        "ProblemJson",
+			contentType: ContentTypeProblemJson,
+			expect:      jsonCodec{},
+		},
+		{
+			name:        "SuffixJson",
+			contentType: "application/vnd.api+json",
+			expect:      jsonCodec{},
+		},
+		{
+			name:        "Xml",
+			contentType: "Text/XML",
+			expect:      xmlCodec{},
+		},
+		{
+			name:        "SuffixXml",
+			contentType: "application/atom+xml",
+			expect:      xmlCodec{},
+		},
+		{
+			name:        "Form",
+			contentType: ContentTypeForm,
+			expect:      formCodec{},
+		},
+		{
+			name:        "Registered",
+			codecs:      registered,
+			contentType: "text/csv",
+			expect:      mockCodec{},
+		},
+		{
+			name:        "RegisteredOverride",
+			codecs:      registered,
+			contentType: "application/vnd.api+json",
+			expect:      mockJsonCodec{},
+		},
+		{
+			name:        "NoCodec",
+			contentType: "text/csv",
+			expect:      nil,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, findCodec(tc.codecs, tc.contentType))
+		})
+	}
+}
+
+func TestFormCodecUnmarshal(t *testing.T) {
+	data := []byte("a=1&a=2&b=3")
+
+	var values url.Values
+	require.NoError(t, formCodec{}.Unmarshal(data, &values))
+	assert.Equal(t, url.Values{"a": {"1", "2"}, "b": {"3"}}, values)
+
+	var m map[string]string
+	require.NoError(t, formCodec{}.Unmarshal(data, &m))
+	assert.Equal(t, map[string]string{"a": "1", "b": "3"}, m)
+
+	var s struct{}
+	assert.Error(t, formCodec{}.Unmarshal(data, &s))
+	assert.Error(t, formCodec{}.Unmarshal([]byte("%zz"), &values))
+}
+
+func TestDecode(t *testing.T) {
+	tt := []struct {
+		name        string
+		codecs      map[string]Codec
+		contentType string
+		body        string
+		expect      mockCore
+		hasError    error
+	}{
+		{
+			name:        "Json",
+			contentType: ContentTypeJson,
+			body:        `{"A":"foo","B":"bar"}`,
+			expect:      mockCore{A: "foo", B: "bar"},
+		},
+		{
+			name:   "NoContentType",
+			body:   `{"A":"foo","B":"bar"}`,
+			expect: mockCore{A: "foo", B: "bar"},
+		},
+		{
+			name:        "Xml",
+			contentType: ContentTypeXml,
+			body:        `<mockCore><A>foo</A><B>bar</B></mockCore>`,
+			expect:      mockCore{A: "foo", B: "bar"},
+		},
+		{
+			name:        "Registered",
+			codecs:      newCodecMap(mockCodec{}),
+			contentType: "text/csv; header=absent",
+			body:        "foo,bar",
+			expect:      mockCore{A: "foo", B: "bar"},
+		},
+		{
+			name:        "NoCodec",
+			contentType: "text/csv",
+			body:        "foo,bar",
+			hasError:    ErrNoCodec,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Response{
+				Body:            []byte(tc.body),
+				ResponseHeaders: http.Header{},
+				codecs:          tc.codecs,
+			}
+			if tc.contentType != "" {
+				r.ResponseHeaders.Set(HeaderContentType, tc.contentType)
+			}
+
+			var have mockCore
+			err := r.Decode(&have)
+			if tc.hasError != nil {
+				assert.ErrorIs(t, err, tc.hasError)
+				return
+			}
+			require.NoError(t, err, "expected no errors")
+			assert.Equal(t, tc.expect, have)
+		})
+	}
+}
+
+func TestRegisteredCodecRequest(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestBody, err := io.ReadAll(r.Body)
+		require.NoError(t, err, "expected no errors")
+		assert.Equal(t, "foo,bar", string(requestBody))
+
+		w.Header().Set(HeaderContentType, "text/csv")
+		w.Write([]byte("bar,foo"))
+	}))
+	defer s.Close()
+
+	c := NewBuild().SetBaseURL(s.URL).RegisterCodec(mockCodec{}).Build()
+	headers := http.Header{HeaderContentType: {"text/csv"}}
+	response, err := c.Post("/api", &mockCore{A: "foo", B: "bar"}, headers)
+	require.NoError(t, err, "expected no errors")
+
+	var have mockCore
+	require.NoError(t, response.Decode(&have))
+	assert.Equal(t, mockCore{A: "bar", B: "foo"}, have)
+}
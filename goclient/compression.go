@@ -0,0 +1,184 @@
+package goclient
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// acceptEncoding lists the content encodings of response bodies that are
+// decoded by the client.
+const acceptEncoding = "gzip, deflate, br, zstd"
+
+// Compression defines how request bodies are compressed. Only request bodies
+// that are encoded by the client, rather than streamed, are compressed.
+type Compression struct {
+	// Encoding is the content encoding of compressed request bodies, which is
+	// one of EncodingGzip, EncodingDeflate or EncodingZstd. An empty encoding
+	// disables compression.
+	Encoding string
+
+	// MinSize is the min size of a request body in bytes before it is
+	// compressed. Smaller bodies are sent uncompressed.
+	MinSize int
+}
+
+// getCompression returns the compression of a request body. The client
+// request takes precedence over the client build.
+func (c *client) getCompression(opts *requestOptions) Compression {
+	if opts.compression != nil {
+		return *opts.compression
+	}
+	if c.builder.compression != nil {
+		return *c.builder.compression
+	}
+	return Compression{}
+}
+
+// compressRequestBody returns the compressed request body and sets its
+// Content-Encoding header. Nil is returned if the request body is not
+// compressed, because compression is disabled, the body is streamed, empty or
+// too small, or a Content-Encoding header is already set.
+func (c *client) compressRequestBody(body *bodyReader, opts *requestOptions) (*bodyReader, error) {
+	compression := c.getCompression(opts)
+	if compression.Encoding == "" || len(body.data) == 0 || len(body.data) < compression.MinSize {
+		return nil, nil
+	}
+	if body.headers.Get(HeaderContentEncoding) != "" {
+		return nil, nil
+	}
+
+	data, err := compress(compression.Encoding, body.data)
+	if err != nil {
+		return nil, err
+	}
+	headers := body.headers.Clone()
+	headers.Set(HeaderContentEncoding, compression.Encoding)
+	return &bodyReader{
+		reader:       bytes.NewReader(data),
+		headers:      headers,
+		data:         data,
+		uncompressed: &bodyReader{reader: bytes.NewReader(body.data), headers: body.headers, data: body.data},
+	}, nil
+}
+
+// compress returns data compressed with the given content encoding.
+func compress(encoding string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case EncodingGzip:
+		w = gzip.NewWriter(&buf)
+	case EncodingDeflate:
+		w = zlib.NewWriter(&buf)
+	case EncodingZstd:
+		encoder, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer encoder.Close()
+		return encoder.EncodeAll(data, nil), nil
+	default:
+		return nil, fmt.Errorf("goclient: unsupported content encoding %q", encoding)
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeResponse replaces an encoded response body with its decoded body and
+// returns the original content encoding. The Content-Encoding and
+// Content-Length headers are removed, since they describe the encoded body. An
+// empty string is returned and the response is left unchanged if the body is
+// not encoded or its encoding is not supported.
+func decodeResponse(response *http.Response) string {
+	encoding := strings.ToLower(strings.TrimSpace(response.Header.Get(HeaderContentEncoding)))
+	switch encoding {
+	case "x-gzip":
+		encoding = EncodingGzip
+	case EncodingGzip, EncodingDeflate, EncodingBrotli, EncodingZstd:
+	default:
+		return ""
+	}
+
+	response.Body = &decodedBody{body: response.Body, encoding: encoding}
+	response.Header.Del(HeaderContentEncoding)
+	response.Header.Del(HeaderContentLength)
+	response.ContentLength = -1
+	response.Uncompressed = true
+	return encoding
+}
+
+// decodedBody is a response body that is decoded as it is read. The decoder is
+// created on the first read, so empty bodies, such as those of HEAD requests,
+// are not rejected for a missing header.
+type decodedBody struct {
+	body     io.ReadCloser
+	encoding string
+	decoder  io.Reader
+	err      error
+}
+
+// Read reads the decoded response body.
+func (d *decodedBody) Read(p []byte) (int, error) {
+	if d.decoder == nil && d.err == nil {
+		d.decoder, d.err = newDecoder(d.encoding, d.body)
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	return d.decoder.Read(p)
+}
+
+// Close releases the decoder and closes the response body.
+func (d *decodedBody) Close() error {
+	if closer, ok := d.decoder.(io.Closer); ok {
+		closer.Close()
+	}
+	return d.body.Close()
+}
+
+// newDecoder returns a reader that decodes r with the given content encoding.
+// Although deflate is defined as zlib, some web services send raw deflate, so
+// both are accepted.
+func newDecoder(encoding string, r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if len(header) == 0 && err == io.EOF {
+		return br, nil
+	}
+
+	switch encoding {
+	case EncodingGzip:
+		return gzip.NewReader(br)
+	case EncodingDeflate:
+		if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	case EncodingBrotli:
+		return brotli.NewReader(br), nil
+	case EncodingZstd:
+		decoder, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("goclient: unsupported content encoding %q", encoding)
+	}
+}
@@ -0,0 +1,314 @@
+package goclient
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decompress(t *testing.T, encoding string, data []byte) string {
+	t.Helper()
+	var r io.Reader
+	var err error
+	switch encoding {
+	case EncodingGzip:
+		r, err = gzip.NewReader(bytes.NewReader(data))
+	case EncodingDeflate:
+		r, err = zlib.NewReader(bytes.NewReader(data))
+	case EncodingZstd:
+		r, err = zstd.NewReader(bytes.NewReader(data))
+	default:
+		return string(data)
+	}
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestCompress(t *testing.T) {
+	tt := []struct {
+		name     string
+		encoding string
+		hasError bool
+	}{
+		{name: "Gzip", encoding: EncodingGzip},
+		{name: "Deflate", encoding: EncodingDeflate},
+		{name: "Zstd", encoding: EncodingZstd},
+		{name: "Unsupported", encoding: "br", hasError: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := compress(tc.encoding, []byte("hello world"))
+			if tc.hasError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "hello world", decompress(t, tc.encoding, have))
+		})
+	}
+}
+
+func TestGetCompression(t *testing.T) {
+	tt := []struct {
+		name   string
+		build  *builder
+		opts   *requestOptions
+		expect Compression
+	}{
+		{name: "Default", build: &builder{}, opts: newRequestOptions(), expect: Compression{}},
+		{
+			name:   "Builder",
+			build:  &builder{compression: &Compression{Encoding: EncodingGzip}},
+			opts:   newRequestOptions(),
+			expect: Compression{Encoding: EncodingGzip},
+		},
+		{
+			name:   "RequestDisables",
+			build:  &builder{compression: &Compression{Encoding: EncodingGzip}},
+			opts:   newRequestOptions(WithCompression(Compression{})),
+			expect: Compression{},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &client{builder: tc.build}
+			assert.Equal(t, tc.expect, c.getCompression(tc.opts))
+		})
+	}
+}
+
+func TestCompressRequestBody(t *testing.T) {
+	tt := []struct {
+		name        string
+		compression Compression
+		body        *bodyReader
+		compressed  bool
+	}{
+		{
+			name:        "Compressed",
+			compression: Compression{Encoding: EncodingGzip},
+			body:        &bodyReader{headers: http.Header{}, data: []byte("hello")},
+			compressed:  true,
+		},
+		{
+			name:        "Disabled",
+			compression: Compression{},
+			body:        &bodyReader{headers: http.Header{}, data: []byte("hello")},
+		},
+		{
+			name:        "BelowMinSize",
+			compression: Compression{Encoding: EncodingGzip, MinSize: 10},
+			body:        &bodyReader{headers: http.Header{}, data: []byte("hello")},
+		},
+		{
+			name:        "Empty",
+			compression: Compression{Encoding: EncodingGzip},
+			body:        &bodyReader{headers: http.Header{}, data: []byte{}},
+		},
+		{
+			name:        "Streamed",
+			compression: Compression{Encoding: EncodingGzip},
+			body:        &bodyReader{headers: http.Header{}, reader: strings.NewReader("hello")},
+		},
+		{
+			name:        "AlreadyEncoded",
+			compression: Compression{Encoding: EncodingGzip},
+			body:        &bodyReader{headers: http.Header{HeaderContentEncoding: {"br"}}, data: []byte("hello")},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &client{builder: &builder{compression: &tc.compression}}
+			have, err := c.compressRequestBody(tc.body, newRequestOptions())
+			require.NoError(t, err)
+			if !tc.compressed {
+				assert.Nil(t, have)
+				return
+			}
+			assert.Equal(t, EncodingGzip, have.headers.Get(HeaderContentEncoding))
+			assert.Empty(t, tc.body.headers.Get(HeaderContentEncoding))
+			assert.Equal(t, "hello", decompress(t, EncodingGzip, have.data))
+			assert.Equal(t, tc.body.data, have.uncompressed.data)
+		})
+	}
+}
+
+func TestDoRequestCompression(t *testing.T) {
+	tt := []struct {
+		name     string
+		encoding string
+		reject   bool
+		expect   []string
+	}{
+		{name: "Gzip", encoding: EncodingGzip, expect: []string{EncodingGzip}},
+		{name: "Deflate", encoding: EncodingDeflate, expect: []string{EncodingDeflate}},
+		{name: "Zstd", encoding: EncodingZstd, expect: []string{EncodingZstd}},
+		{name: "UnsupportedMediaType", encoding: EncodingGzip, reject: true, expect: []string{EncodingGzip, ""}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var encodings []string
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				encoding := r.Header.Get(HeaderContentEncoding)
+				encodings = append(encodings, encoding)
+				b, _ := io.ReadAll(r.Body)
+				if tc.reject && encoding != "" {
+					w.WriteHeader(http.StatusUnsupportedMediaType)
+					return
+				}
+				w.Write([]byte(decompress(t, encoding, b)))
+			}))
+			defer s.Close()
+
+			c := NewBuild().SetRequestCompression(Compression{Encoding: tc.encoding}).Build().(*client)
+			response, err := c.doRequest(context.Background(), http.MethodPost, s.URL, map[string]string{"name": "test"})
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.JSONEq(t, `{"name":"test"}`, string(response.Body))
+			assert.Equal(t, tc.expect, encodings)
+		})
+	}
+}
+
+func encode(t *testing.T, encoding string, data string) []byte {
+	t.Helper()
+	if encoding == EncodingBrotli {
+		var buf bytes.Buffer
+		w := brotli.NewWriter(&buf)
+		_, err := w.Write([]byte(data))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+	if encoding == "raw deflate" {
+		var buf bytes.Buffer
+		w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(data))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+	b, err := compress(encoding, []byte(data))
+	require.NoError(t, err)
+	return b
+}
+
+func TestDecodeResponse(t *testing.T) {
+	tt := []struct {
+		name     string
+		encoding string
+		body     []byte
+		expect   string
+		decoded  string
+	}{
+		{name: "Gzip", encoding: "gzip", body: encode(t, EncodingGzip, "hello"), expect: "gzip", decoded: "hello"},
+		{name: "XGzip", encoding: "x-gzip", body: encode(t, EncodingGzip, "hello"), expect: "gzip", decoded: "hello"},
+		{name: "Deflate", encoding: "deflate", body: encode(t, EncodingDeflate, "hello"), expect: "deflate", decoded: "hello"},
+		{name: "RawDeflate", encoding: "deflate", body: encode(t, "raw deflate", "hello"), expect: "deflate", decoded: "hello"},
+		{name: "Brotli", encoding: "BR", body: encode(t, EncodingBrotli, "hello"), expect: "br", decoded: "hello"},
+		{name: "Zstd", encoding: "zstd", body: encode(t, EncodingZstd, "hello"), expect: "zstd", decoded: "hello"},
+		{name: "Empty", encoding: "gzip", body: []byte{}, expect: "gzip", decoded: ""},
+		{name: "Identity", encoding: "", body: []byte("hello"), expect: "", decoded: "hello"},
+		{name: "Unsupported", encoding: "compress", body: []byte("hello"), expect: "", decoded: "hello"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			response := &http.Response{
+				Header:        http.Header{HeaderContentLength: {"5"}},
+				Body:          io.NopCloser(bytes.NewReader(tc.body)),
+				ContentLength: 5,
+			}
+			if tc.encoding != "" {
+				response.Header.Set(HeaderContentEncoding, tc.encoding)
+			}
+			have := decodeResponse(response)
+			assert.Equal(t, tc.expect, have)
+
+			b, err := io.ReadAll(response.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.decoded, string(b))
+			assert.NoError(t, response.Body.Close())
+			if tc.expect != "" {
+				assert.Empty(t, response.Header.Get(HeaderContentEncoding))
+				assert.Empty(t, response.Header.Get(HeaderContentLength))
+				assert.Equal(t, int64(-1), response.ContentLength)
+			}
+		})
+	}
+}
+
+func TestDoRequestDecompression(t *testing.T) {
+	tt := []struct {
+		name     string
+		encoding string
+		options  []RequestOption
+		accept   string
+		expect   string
+		decoded  string
+	}{
+		{name: "Gzip", encoding: EncodingGzip, accept: acceptEncoding, expect: "hello", decoded: EncodingGzip},
+		{name: "Brotli", encoding: EncodingBrotli, accept: acceptEncoding, expect: "hello", decoded: EncodingBrotli},
+		{name: "Zstd", encoding: EncodingZstd, accept: acceptEncoding, expect: "hello", decoded: EncodingZstd},
+		{name: "Stream", encoding: EncodingZstd, options: []RequestOption{WithStream()}, accept: acceptEncoding, expect: "hello", decoded: EncodingZstd},
+		{
+			name:     "AcceptEncodingSetByRequest",
+			encoding: EncodingBrotli,
+			options:  []RequestOption{WithHeaders(http.Header{HeaderAcceptEncoding: {EncodingBrotli}})},
+			accept:   EncodingBrotli,
+			expect:   string(encode(t, EncodingBrotli, "hello")),
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var accept string
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				accept = r.Header.Get(HeaderAcceptEncoding)
+				w.Header().Set(HeaderContentEncoding, tc.encoding)
+				w.Write(encode(t, tc.encoding, "hello"))
+			}))
+			defer s.Close()
+
+			c := &client{builder: &builder{}}
+			response, err := c.doRequest(context.Background(), http.MethodGet, s.URL, nil, tc.options...)
+			require.NoError(t, err)
+			defer response.Close()
+
+			body := response.Body
+			if response.Stream != nil {
+				body, err = io.ReadAll(response.Stream)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.accept, accept)
+			assert.Equal(t, tc.expect, string(body))
+			assert.Equal(t, tc.decoded, response.ContentEncoding)
+		})
+	}
+}
+
+func TestDoRequestDecompressionLimit(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentEncoding, EncodingGzip)
+		w.Write(encode(t, EncodingGzip, strings.Repeat("a", 1024)))
+	}))
+	defer s.Close()
+
+	c := &client{builder: &builder{maxResponseBodySize: 512}}
+	_, err := c.doRequest(context.Background(), http.MethodGet, s.URL, nil)
+	var tooLarge *ErrResponseTooLarge
+	assert.ErrorAs(t, err, &tooLarge)
+}
@@ -0,0 +1,165 @@
+package goclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxErrorBodySize is the max number of bytes of a response body kept by a
+// HTTPError.
+const maxErrorBodySize = 4 << 10
+
+// ErrRequestCancelled is returned when a client request is abandoned because
+// its context was cancelled or its deadline was exceeded. The underlying
+// context error is also wrapped, so errors.Is can match either one.
+var ErrRequestCancelled = errors.New("goclient: request cancelled")
+
+// ErrRateLimited is returned when a request is rejected without being sent
+// because the client-side rate limit is exceeded.
+var ErrRateLimited = errors.New("goclient: rate limit exceeded")
+
+// wrapContextError returns err wrapped with ErrRequestCancelled if the context
+// of the request is done. Otherwise, err is returned unchanged.
+func wrapContextError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrRequestCancelled, err)
+}
+
+// ErrCircuitOpen is returned when a request is rejected without being sent
+// because the circuit breaker of its host is open.
+type ErrCircuitOpen struct {
+	Host    string
+	RetryAt time.Time
+}
+
+// Error returns the error message of a rejected request.
+func (e *ErrCircuitOpen) Error() string {
+	return fmt.Sprintf("goclient: circuit breaker open for host %q", e.Host)
+}
+
+// HTTPError is returned for responses with a non-success status code if status
+// errors are enabled as part of the client build. The response is still
+// returned alongside the error.
+type HTTPError struct {
+	Method     string
+	URL        string
+	Status     string
+	StatusCode int
+	Headers    http.Header
+
+	// Body holds up to the first 4KiB of the response body.
+	Body []byte
+
+	// Problem holds the problem details object of an application/problem+json
+	// response. It is nil for any other response.
+	Problem *Problem
+}
+
+// newHTTPError returns a HTTPError for the response of the request or nil if
+// the response has a 2xx status code.
+func newHTTPError(request *http.Request, response *Response) *HTTPError {
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+	body := response.Body
+	if len(body) > maxErrorBodySize {
+		body = body[:maxErrorBodySize]
+	}
+
+	// A malformed problem details object must not hide the status error, so
+	// it is only attached if it can be decoded.
+	problem, _ := response.Problem()
+	httpErr := &HTTPError{
+		Status:     response.Status,
+		StatusCode: response.StatusCode,
+		Headers:    response.ResponseHeaders,
+		Body:       body,
+		Problem:    problem,
+	}
+	if request != nil {
+		httpErr.Method = request.Method
+		httpErr.URL = request.URL.Redacted()
+	}
+	return httpErr
+}
+
+// Error returns the error message of a non-success response.
+func (e *HTTPError) Error() string {
+	status := e.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	if e.Problem != nil && e.Problem.Detail != "" {
+		return fmt.Sprintf("goclient: %s %s: %s: %s", e.Method, e.URL, status, e.Problem.Detail)
+	}
+	return fmt.Sprintf("goclient: %s %s: %s", e.Method, e.URL, status)
+}
+
+// IsNotFound reports whether err is a HTTPError with a 404 status code.
+func IsNotFound(err error) bool {
+	return IsStatus(err, http.StatusNotFound)
+}
+
+// IsClientError reports whether err is a HTTPError with a 4xx status code.
+func IsClientError(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode >= 400 && httpErr.StatusCode < 500
+}
+
+// IsServerError reports whether err is a HTTPError with a 5xx status code.
+func IsServerError(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode >= 500 && httpErr.StatusCode < 600
+}
+
+// IsStatus reports whether err is a HTTPError with the given status code.
+func IsStatus(err error, statusCode int) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == statusCode
+}
+
+// ErrResponseTooLarge is returned when a response body exceeds the max size
+// defined as part of the client build or client request.
+type ErrResponseTooLarge struct {
+	// Limit is the max size of the response body in bytes.
+	Limit int64
+
+	// Read is the number of bytes read before the limit was exceeded. It is
+	// zero if the declared Content-Length already exceeds the limit.
+	Read int64
+
+	// ContentLength is the declared Content-Length of the response or -1 if
+	// it is unknown.
+	ContentLength int64
+}
+
+// Error returns the error message of an oversized response body.
+func (e *ErrResponseTooLarge) Error() string {
+	if e.ContentLength >= 0 {
+		return fmt.Sprintf("goclient: response body exceeds %d bytes (read %d, Content-Length %d)",
+			e.Limit, e.Read, e.ContentLength)
+	}
+	return fmt.Sprintf("goclient: response body exceeds %d bytes (read %d)", e.Limit, e.Read)
+}
+
+// ErrPinMismatch is returned when none of the public keys of the verified
+// certificate chain of a web service match the certificate pins of its host.
+type ErrPinMismatch struct {
+	Host string
+
+	// Presented are the base64 SPKI SHA-256 hashes of the certificates of the
+	// verified chains, starting with the leaf certificate.
+	Presented []string
+}
+
+// Error returns the error message of a pin mismatch.
+func (e *ErrPinMismatch) Error() string {
+	return fmt.Sprintf("goclient: certificate pin mismatch for host %q, presented %s",
+		e.Host, strings.Join(e.Presented, ", "))
+}
@@ -0,0 +1,185 @@
+package goclient
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// encodeForm returns the application/x-www-form-urlencoded encoding of body,
+// which must be url.Values, a map of strings or a struct. Struct fields are
+// named by their form tag, using the same conventions as the json package.
+func encodeForm(body any) ([]byte, error) {
+	var values url.Values
+	switch v := body.(type) {
+	case url.Values:
+		values = v
+	case map[string][]string:
+		values = v
+	case map[string]string:
+		values = make(url.Values, len(v))
+		for key, value := range v {
+			values.Set(key, value)
+		}
+	default:
+		var err error
+		if values, err = structValues(body, "form"); err != nil {
+			return nil, err
+		}
+	}
+	return []byte(values.Encode()), nil
+}
+
+// structValues returns the fields of a struct, or a pointer to one, as URL
+// values. Each field is named by the given struct tag or by its field name if
+// the tag is absent. A tag of "-" skips the field and the omitempty option
+// skips it if it has the zero value. Slice and array fields are repeated for
+// each element. Embedded structs without a tag are flattened. time.Time fields
+// are formatted as RFC 3339, unless the unix or unixmilli option is set or a
+// layout tag defines the time format.
+func structValues(v any, tag string) (url.Values, error) {
+	rv := indirectValue(reflect.ValueOf(v))
+	if !rv.IsValid() {
+		return url.Values{}, nil
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("goclient: cannot encode %T as %s values", v, tag)
+	}
+
+	values := make(url.Values)
+	if err := addStructValues(values, rv, tag); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// addStructValues adds the fields of the struct value rv to values.
+func addStructValues(values url.Values, rv reflect.Value, tag string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tagValue, tagged := field.Tag.Lookup(tag)
+		name, opts, _ := strings.Cut(tagValue, ",")
+		if name == "-" {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if field.Anonymous && !tagged {
+			if ev := indirectValue(fv); ev.Kind() == reflect.Struct {
+				if err := addStructValues(values, ev, tag); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if hasTagOption(opts, "omitempty") && fv.IsZero() {
+			continue
+		}
+
+		fv = indirectValue(fv)
+		if !fv.IsValid() {
+			continue
+		}
+		if fv.Kind() == reflect.Array || fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			for j := 0; j < fv.Len(); j++ {
+				s, err := formatFieldValue(field, opts, fv.Index(j))
+				if err != nil {
+					return fmt.Errorf("goclient: field %s: %w", field.Name, err)
+				}
+				values.Add(name, s)
+			}
+			continue
+		}
+
+		s, err := formatFieldValue(field, opts, fv)
+		if err != nil {
+			return fmt.Errorf("goclient: field %s: %w", field.Name, err)
+		}
+		values.Add(name, s)
+	}
+	return nil
+}
+
+// hasTagOption reports whether the comma-separated options of a struct tag
+// include the named option.
+func hasTagOption(opts, name string) bool {
+	return strings.Contains(","+opts+",", ","+name+",")
+}
+
+// formatFieldValue returns the string representation of a single value of a
+// struct field. time.Time values are formatted according to the tag options or
+// layout tag of the field.
+func formatFieldValue(field reflect.StructField, opts string, rv reflect.Value) (string, error) {
+	if iv := indirectValue(rv); iv.IsValid() && iv.Type() == reflect.TypeOf(time.Time{}) {
+		t := iv.Interface().(time.Time)
+		switch {
+		case hasTagOption(opts, "unix"):
+			return strconv.FormatInt(t.Unix(), 10), nil
+		case hasTagOption(opts, "unixmilli"):
+			return strconv.FormatInt(t.UnixMilli(), 10), nil
+		}
+		if layout := field.Tag.Get("layout"); layout != "" {
+			return t.Format(layout), nil
+		}
+	}
+	return formatValue(rv)
+}
+
+// indirectValue follows pointers and interfaces until it reaches a concrete
+// value. The zero value is returned if a nil pointer or interface is reached.
+func indirectValue(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+// formatValue returns the string representation of a single field value.
+// time.Time values are formatted as RFC 3339.
+func formatValue(rv reflect.Value) (string, error) {
+	if rv = indirectValue(rv); !rv.IsValid() {
+		return "", nil
+	}
+
+	switch v := rv.Interface().(type) {
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	case encoding.TextMarshaler:
+		b, err := v.MarshalText()
+		return string(b), err
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, rv.Type().Bits()), nil
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return string(rv.Bytes()), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type %s", rv.Type())
+}
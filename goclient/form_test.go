@@ -0,0 +1,97 @@
+package goclient
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockFormEmbedded struct {
+	Page int `form:"page"`
+}
+
+type mockFormStudent struct {
+	mockFormEmbedded
+	Name     string    `form:"name"`
+	Age      int       `form:"age,omitempty"`
+	Grade    *int      `form:"grade"`
+	Active   bool      `form:"active"`
+	Score    float64   `form:"score"`
+	Subjects []string  `form:"subject"`
+	Enrolled time.Time `form:"enrolled"`
+	Secret   string    `form:"-"`
+	Untagged string
+	private  string
+}
+
+func TestEncodeForm(t *testing.T) {
+	grade := 7
+	tt := []struct {
+		name     string
+		body     any
+		expect   string
+		hasError bool
+	}{
+		{
+			name:   "Values",
+			body:   url.Values{"b": {"2"}, "a": {"1", "3"}},
+			expect: "a=1&a=3&b=2",
+		},
+		{
+			name:   "MapSlice",
+			body:   map[string][]string{"a": {"1"}},
+			expect: "a=1",
+		},
+		{
+			name:   "MapString",
+			body:   map[string]string{"name": "foo bar", "id": "1&2"},
+			expect: "id=1%262&name=foo+bar",
+		},
+		{
+			name: "Struct",
+			body: &mockFormStudent{
+				mockFormEmbedded: mockFormEmbedded{Page: 2},
+				Name:             "foobar",
+				Grade:            &grade,
+				Active:           true,
+				Score:            9.5,
+				Subjects:         []string{"maths", "art"},
+				Enrolled:         time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC),
+				Secret:           "secret",
+				Untagged:         "untagged",
+				private:          "private",
+			},
+			expect: "Untagged=untagged&active=true&enrolled=2023-06-01T12%3A00%3A00Z&grade=7&name=foobar&page=2&score=9.5&subject=maths&subject=art",
+		},
+		{
+			name:   "NilPointer",
+			body:   (*mockFormStudent)(nil),
+			expect: "",
+		},
+		{
+			name:     "UnsupportedBody",
+			body:     42,
+			hasError: true,
+		},
+		{
+			name:     "UnsupportedField",
+			body:     struct{ C chan int }{C: make(chan int)},
+			hasError: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := encodeForm(tc.body)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Nil(t, have)
+				return
+			}
+			require.NoError(t, err, "expected no errors")
+			assert.Equal(t, tc.expect, string(have))
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package goclient
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ResponseError is returned by the typed request helpers with an error body
+// type when a web service responds with a non-success status code and the
+// response body can be decoded into the error body type.
+type ResponseError[E any] struct {
+	HTTPError *HTTPError
+	Value     E
+}
+
+// Error returns the error message of the underlying HTTPError.
+func (e *ResponseError[E]) Error() string {
+	return e.HTTPError.Error()
+}
+
+// Unwrap returns the underlying HTTPError, so the status predicates such as
+// IsNotFound can be used with a ResponseError.
+func (e *ResponseError[E]) Unwrap() error {
+	return e.HTTPError
+}
+
+// GetJSON issues a GET request and decodes the JSON response body into T. A
+// non-success response returns a HTTPError.
+func GetJSON[T any](c Client, endpoint string, headers ...http.Header) (T, *Response, error) {
+	return decodeJSON[T, struct{}](false)(c.Get(endpoint, headers...))
+}
+
+// PutJSON issues a PUT request with req encoded as the JSON request body
+// and decodes the JSON response body into Resp. Content-Type is set to
+// application/json unless it is already set. A non-success response returns a
+// HTTPError.
+func PutJSON[Req, Resp any](c Client, endpoint string, req Req, headers ...http.Header) (Resp, *Response, error) {
+	body, requestHeaders, err := encodeJSON(req, headers...)
+	if err != nil {
+		return decodeJSON[Resp, struct{}](false)(nil, err)
+	}
+	return decodeJSON[Resp, struct{}](false)(c.Put(endpoint, body, requestHeaders))
+}
+
+// PostJSON issues a POST request with req encoded as the JSON request body
+// and decodes the JSON response body into Resp. Content-Type is set to
+// application/json unless it is already set. A non-success response returns a
+// HTTPError.
+func PostJSON[Req, Resp any](c Client, endpoint string, req Req, headers ...http.Header) (Resp, *Response, error) {
+	body, requestHeaders, err := encodeJSON(req, headers...)
+	if err != nil {
+		return decodeJSON[Resp, struct{}](false)(nil, err)
+	}
+	return decodeJSON[Resp, struct{}](false)(c.Post(endpoint, body, requestHeaders))
+}
+
+// PatchJSON issues a PATCH request with req encoded as the JSON request body
+// and decodes the JSON response body into Resp. Content-Type is set to
+// application/json unless it is already set. A non-success response returns a
+// HTTPError.
+func PatchJSON[Req, Resp any](c Client, endpoint string, req Req, headers ...http.Header) (Resp, *Response, error) {
+	body, requestHeaders, err := encodeJSON(req, headers...)
+	if err != nil {
+		return decodeJSON[Resp, struct{}](false)(nil, err)
+	}
+	return decodeJSON[Resp, struct{}](false)(c.Patch(endpoint, body, requestHeaders))
+}
+
+// DeleteJSON issues a DELETE request and decodes the JSON response body into
+// T. A non-success response returns a HTTPError.
+func DeleteJSON[T any](c Client, endpoint string, headers ...http.Header) (T, *Response, error) {
+	return decodeJSON[T, struct{}](false)(c.Delete(endpoint, headers...))
+}
+
+// GetJSONWithError is like GetJSON, but a non-success response body is
+// decoded into E and returned as a ResponseError.
+func GetJSONWithError[T, E any](c Client, endpoint string, headers ...http.Header) (T, *Response, error) {
+	return decodeJSON[T, E](true)(c.Get(endpoint, headers...))
+}
+
+// PutJSONWithError is like PutJSON, but a non-success response body is
+// decoded into E and returned as a ResponseError.
+func PutJSONWithError[Req, Resp, E any](c Client, endpoint string, req Req, headers ...http.Header) (Resp, *Response, error) {
+	body, requestHeaders, err := encodeJSON(req, headers...)
+	if err != nil {
+		return decodeJSON[Resp, E](true)(nil, err)
+	}
+	return decodeJSON[Resp, E](true)(c.Put(endpoint, body, requestHeaders))
+}
+
+// PostJSONWithError is like PostJSON, but a non-success response body is
+// decoded into E and returned as a ResponseError.
+func PostJSONWithError[Req, Resp, E any](c Client, endpoint string, req Req, headers ...http.Header) (Resp, *Response, error) {
+	body, requestHeaders, err := encodeJSON(req, headers...)
+	if err != nil {
+		return decodeJSON[Resp, E](true)(nil, err)
+	}
+	return decodeJSON[Resp, E](true)(c.Post(endpoint, body, requestHeaders))
+}
+
+// PatchJSONWithError is like PatchJSON, but a non-success response body is
+// decoded into E and returned as a ResponseError.
+func PatchJSONWithError[Req, Resp, E any](c Client, endpoint string, req Req, headers ...http.Header) (Resp, *Response, error) {
+	body, requestHeaders, err := encodeJSON(req, headers...)
+	if err != nil {
+		return decodeJSON[Resp, E](true)(nil, err)
+	}
+	return decodeJSON[Resp, E](true)(c.Patch(endpoint, body, requestHeaders))
+}
+
+// DeleteJSONWithError is like DeleteJSON, but a non-success response body is
+// decoded into E and returned as a ResponseError.
+func DeleteJSONWithError[T, E any](c Client, endpoint string, headers ...http.Header) (T, *Response, error) {
+	return decodeJSON[T, E](true)(c.Delete(endpoint, headers...))
+}
+
+// encodeJSON returns req encoded as a JSON request body, along with the
+// request headers from jsonHeaders. The body is returned as json.RawMessage,
+// so it is sent unchanged whatever the type of req or the codecs of the client
+// build.
+func encodeJSON[Req any](req Req, headers ...http.Header) (json.RawMessage, http.Header, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, jsonHeaders(headers...), nil
+}
+
+// jsonHeaders returns a clone of the request headers with Content-Type set to
+// application/json, unless it is already set.
+func jsonHeaders(headers ...http.Header) http.Header {
+	requestHeaders := getRequestHeaders(headers...).Clone()
+	if requestHeaders == nil {
+		requestHeaders = make(http.Header)
+	}
+	if requestHeaders.Get(HeaderContentType) == "" {
+		requestHeaders.Set(HeaderContentType, ContentTypeJson)
+	}
+	return requestHeaders
+}
+
+// decodeJSON returns a function that decodes the result of a client request.
+// A success response body is decoded into T. A non-success response returns a
+// HTTPError, wrapped in a ResponseError if withError is set and the response
+// body can be decoded into E.
+func decodeJSON[T, E any](withError bool) func(*Response, error) (T, *Response, error) {
+	return func(response *Response, err error) (T, *Response, error) {
+		var value T
+		var httpErr *HTTPError
+		if err != nil && !errors.As(err, &httpErr) {
+			return value, response, err
+		}
+		if httpErr == nil {
+			httpErr = newHTTPError(response.request, response)
+		}
+
+		if httpErr != nil {
+			if !withError {
+				return value, response, httpErr
+			}
+			var errorBody E
+			if err := json.Unmarshal(response.BytesBody(), &errorBody); err != nil {
+				return value, response, httpErr
+			}
+			return value, response, &ResponseError[E]{HTTPError: httpErr, Value: errorBody}
+		}
+
+		if len(response.BytesBody()) == 0 {
+			return value, response, nil
+		}
+		if err := response.UnmarshalJson(&value); err != nil {
+			return value, response, err
+		}
+		return value, response, nil
+	}
+}
@@ -0,0 +1,299 @@
+package goclient
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockStudent struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type mockErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func newGenericServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/students/1":
+			if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+				requestBody, err := io.ReadAll(r.Body)
+				require.NoError(t, err, "expected no errors")
+				assert.Equal(t, `{"id":1,"name":"foobar"}`, string(requestBody))
+			}
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id": 1, "name": "foobar"}`))
+		case "/students/2":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": "not_found", "message": "no such student"}`))
+		case "/students/3":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`<html>`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{`))
+		}
+	}))
+}
+
+func TestJSONHelpers(t *testing.T) {
+	s := newGenericServer(t)
+	defer s.Close()
+
+	c := NewBuild().SetBaseURL(s.URL).Build()
+	student := mockStudent{ID: 1, Name: "foobar"}
+
+	t.Run("GetJSON", func(t *testing.T) {
+		have, response, err := GetJSON[mockStudent](c, "/students/1")
+		require.NoError(t, err, "expected no errors")
+		assert.Equal(t, student, have)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	t.Run("PutJSON", func(t *testing.T) {
+		have, _, err := PutJSON[mockStudent, mockStudent](c, "/students/1", student)
+		require.NoError(t, err, "expected no errors")
+		assert.Equal(t, student, have)
+	})
+
+	t.Run("PostJSON", func(t *testing.T) {
+		have, _, err := PostJSON[mockStudent, mockStudent](c, "/students/1", student)
+		require.NoError(t, err, "expected no errors")
+		assert.Equal(t, student, have)
+	})
+
+	t.Run("PatchJSON", func(t *testing.T) {
+		have, _, err := PatchJSON[mockStudent, mockStudent](c, "/students/1", student)
+		require.NoError(t, err, "expected no errors")
+		assert.Equal(t, student, have)
+	})
+
+	t.Run("DeleteJSON", func(t *testing.T) {
+		have, response, err := DeleteJSON[mockStudent](c, "/students/1")
+		require.NoError(t, err, "expected no errors")
+		assert.Zero(t, have)
+		assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		have, response, err := GetJSON[mockStudent](c, "/students/2")
+		assert.True(t, IsNotFound(err))
+		assert.Zero(t, have)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+		var httpErr *HTTPError
+		require.True(t, errors.As(err, &httpErr))
+		assert.Equal(t, http.MethodGet, httpErr.Method)
+		assert.Equal(t, s.URL+"/students/2", httpErr.URL)
+	})
+
+	t.Run("DecodeError", func(t *testing.T) {
+		_, response, err := GetJSON[mockStudent](c, "/malformed")
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	t.Run("RequestError", func(t *testing.T) {
+		_, response, err := PostJSON[chan int, mockStudent](c, "/students/1", make(chan int))
+		assert.Error(t, err)
+		assert.Nil(t, response)
+	})
+}
+
+func TestJSONHelpersWithError(t *testing.T) {
+	s := newGenericServer(t)
+	defer s.Close()
+
+	tt := []struct {
+		name   string
+		build  Builder
+		call   func(c Client, endpoint string) (mockStudent, *Response, error)
+		expect mockErrorBody
+	}{
+		{
+			name:  "GetJSONWithError",
+			build: NewBuild().SetBaseURL(s.URL),
+			call: func(c Client, endpoint string) (mockStudent, *Response, error) {
+				return GetJSONWithError[mockStudent, mockErrorBody](c, endpoint)
+			},
+		},
+		{
+			name:  "PutJSONWithError",
+			build: NewBuild().SetBaseURL(s.URL),
+			call: func(c Client, endpoint string) (mockStudent, *Response, error) {
+				return PutJSONWithError[mockStudent, mockStudent, mockErrorBody](c, endpoint, mockStudent{ID: 1, Name: "foobar"})
+			},
+		},
+		{
+			name:  "PostJSONWithError",
+			build: NewBuild().SetBaseURL(s.URL).SetStatusErrors(true),
+			call: func(c Client, endpoint string) (mockStudent, *Response, error) {
+				return PostJSONWithError[mockStudent, mockStudent, mockErrorBody](c, endpoint, mockStudent{ID: 1, Name: "foobar"})
+			},
+		},
+		{
+			name:  "PatchJSONWithError",
+			build: NewBuild().SetBaseURL(s.URL),
+			call: func(c Client, endpoint string) (mockStudent, *Response, error) {
+				return PatchJSONWithError[mockStudent, mockStudent, mockErrorBody](c, endpoint, mockStudent{ID: 1, Name: "foobar"})
+			},
+		},
+		{
+			name:  "DeleteJSONWithError",
+			build: NewBuild().SetBaseURL(s.URL),
+			call: func(c Client, endpoint string) (mockStudent, *Response, error) {
+				return DeleteJSONWithError[mockStudent, mockErrorBody](c, endpoint)
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := tc.build.Build()
+
+			_, _, err := tc.call(c, "/students/1")
+			require.NoError(t, err, "expected no errors")
+
+			_, response, err := tc.call(c, "/students/2")
+			var responseErr *ResponseError[mockErrorBody]
+			require.True(t, errors.As(err, &responseErr))
+			assert.Equal(t, mockErrorBody{Code: "not_found", Message: "no such student"}, responseErr.Value)
+			assert.True(t, IsNotFound(err))
+			assert.Equal(t, responseErr.HTTPError.Error(), err.Error())
+			assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+			// An error body that cannot be decoded still returns a HTTPError.
+			_, _, err = tc.call(c, "/students/3")
+			assert.False(t, errors.As(err, &responseErr))
+			assert.True(t, IsServerError(err))
+		})
+	}
+}
+
+func TestJSONHelpersContentType(t *testing.T) {
+	var contentType string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get(HeaderContentType)
+		w.Write([]byte(`{"id": 1, "name": "foobar"}`))
+	}))
+	defer s.Close()
+
+	c := NewBuild().SetBaseURL(s.URL).Build()
+	student := mockStudent{ID: 1, Name: "foobar"}
+
+	t.Run("Default", func(t *testing.T) {
+		headers := http.Header{HeaderAuthorization: {"Basic token"}}
+		_, _, err := PostJSON[mockStudent, mockStudent](c, "/students", student, headers)
+		require.NoError(t, err, "expected no errors")
+		assert.Equal(t, ContentTypeJson, contentType)
+		assert.Equal(t, http.Header{HeaderAuthorization: {"Basic token"}}, headers)
+	})
+	t.Run("RequestContentType", func(t *testing.T) {
+		headers := http.Header{HeaderContentType: {"application/vnd.student+json"}}
+		_, _, err := PutJSON[mockStudent, mockStudent](c, "/students", student, headers)
+		require.NoError(t, err, "expected no errors")
+		assert.Equal(t, "application/vnd.student+json", contentType)
+	})
+}
+
+func TestJSONHelpersRequestBody(t *testing.T) {
+	var requestBody, contentType string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err, "expected no errors")
+		requestBody, contentType = string(b), r.Header.Get(HeaderContentType)
+		w.Write([]byte(`{"id": 1, "name": "foobar"}`))
+	}))
+	defer s.Close()
+
+	c := NewBuild().SetBaseURL(s.URL).Build()
+	xmlClient := NewBuild().
+		SetBaseURL(s.URL).
+		SetRequestHeaders(http.Header{HeaderContentType: {ContentTypeXml}}).
+		Build()
+
+	tt := []struct {
+		name        string
+		do          func() (mockStudent, *Response, error)
+		expect      string
+		contentType string
+	}{
+		{
+			name: "String",
+			do: func() (mockStudent, *Response, error) {
+				return PostJSON[string, mockStudent](c, "/students", "hello")
+			},
+			expect:      `"hello"`,
+			contentType: ContentTypeJson,
+		},
+		{
+			name: "Bytes",
+			do: func() (mockStudent, *Response, error) {
+				return PutJSON[[]byte, mockStudent](c, "/students", []byte("hello"))
+			},
+			expect:      `"aGVsbG8="`,
+			contentType: ContentTypeJson,
+		},
+		{
+			name: "RawMessage",
+			do: func() (mockStudent, *Response, error) {
+				return PatchJSON[json.RawMessage, mockStudent](c, "/students", json.RawMessage(`{"id":1}`))
+			},
+			expect:      `{"id":1}`,
+			contentType: ContentTypeJson,
+		},
+		{
+			name: "BuildContentTypeXml",
+			do: func() (mockStudent, *Response, error) {
+				return PostJSON[mockStudent, mockStudent](xmlClient, "/students", mockStudent{ID: 1, Name: "foobar"})
+			},
+			expect:      `{"id":1,"name":"foobar"}`,
+			contentType: ContentTypeJson,
+		},
+		{
+			name: "RequestContentType",
+			do: func() (mockStudent, *Response, error) {
+				headers := http.Header{HeaderContentType: {"application/vnd.student+json"}}
+				return PostJSONWithError[mockStudent, mockStudent, mockErrorBody](c, "/students", mockStudent{ID: 1}, headers)
+			},
+			expect:      `{"id":1,"name":""}`,
+			contentType: "application/vnd.student+json",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			have, _, err := tc.do()
+			require.NoError(t, err, "expected no errors")
+			assert.Equal(t, mockStudent{ID: 1, Name: "foobar"}, have)
+			assert.Equal(t, tc.expect, requestBody)
+			assert.Equal(t, tc.contentType, contentType)
+		})
+	}
+
+	t.Run("HeadersNotModified", func(t *testing.T) {
+		headers := http.Header{HeaderAuthorization: {"Basic token"}}
+		_, _, err := PostJSON[string, mockStudent](c, "/students", "hello", headers)
+		require.NoError(t, err, "expected no errors")
+		assert.Equal(t, http.Header{HeaderAuthorization: {"Basic token"}}, headers)
+	})
+	t.Run("MarshalError", func(t *testing.T) {
+		requestBody = ""
+		_, response, err := PostJSON[chan int, mockStudent](c, "/students", make(chan int))
+		assert.Error(t, err)
+		assert.Nil(t, response)
+		assert.Empty(t, requestBody, "request should not be sent")
+	})
+}
@@ -1,7 +1,9 @@
 package goclient
 
 import (
+	"mime"
 	"net/http"
+	"strings"
 )
 
 // These constants represent standard HTTP field names and field name values.
@@ -9,18 +11,54 @@ import (
 // removing an existing field name entirely. Unlike the values for field names,
 // some values can be arbitrary for field name values.
 const (
-	HeaderAccept        = "Accept"
-	HeaderAuthorization = "Authorization"
-	HeaderContentLength = "Content-Length"
-	HeaderContentType   = "Content-Type"
-	HeaderUserAgent     = "User-Agent"
-	HeaderKeepAlive     = "Connection"
-
-	ContentTypeJson  = "application/json"
-	DefaultUserAgent = "go-http"
-	DefaultKeepAlive = "Keep-Alive"
+	HeaderAccept          = "Accept"
+	HeaderAcceptEncoding  = "Accept-Encoding"
+	HeaderAuthorization   = "Authorization"
+	HeaderContentEncoding = "Content-Encoding"
+	HeaderContentLength   = "Content-Length"
+	HeaderContentType     = "Content-Type"
+	HeaderRetryAfter      = "Retry-After"
+	HeaderUserAgent       = "User-Agent"
+	HeaderKeepAlive       = "Connection"
+
+	ContentTypeForm        = "application/x-www-form-urlencoded"
+	ContentTypeJson        = "application/json"
+	ContentTypeProblemJson = "application/problem+json"
+	ContentTypeTextXml     = "text/xml"
+	ContentTypeXml         = "application/xml"
+	DefaultUserAgent       = "go-http"
+	DefaultKeepAlive       = "Keep-Alive"
+
+	EncodingBrotli  = "br"
+	EncodingDeflate = "deflate"
+	EncodingGzip    = "gzip"
+	EncodingZstd    = "zstd"
+)
+
+// HeaderMergePolicy defines how request headers defined as part of a client
+// request are merged with the ones defined as part of a client build.
+type HeaderMergePolicy int
+
+const (
+	// HeaderReplace replaces all values of a build header with the values of
+	// the request header of the same name.
+	HeaderReplace HeaderMergePolicy = iota
+
+	// HeaderAppend appends the values of a request header to the values of
+	// the build header of the same name.
+	HeaderAppend
 )
 
+// getMediaType returns the lowercase media type of a Content-Type header value
+// without any parameters.
+func getMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
+
 // getRequestHeaders returns request headers that are defined as part of a
 // client request. A nil return value is the equivalent of an empty map.
 func getRequestHeaders(headers ...http.Header) http.Header {
@@ -30,22 +68,37 @@ func getRequestHeaders(headers ...http.Header) http.Header {
 	return nil
 }
 
-// joinHeaders returns a map of consolidated HTTP headers defined as part of a
-// client build or a client request. If a header has been defined in both, the
-// client request takes precedence. It also ensures a name for the user agent
-// is set.
-func (c *client) joinRequestHeaders(headers http.Header) http.Header {
+// joinRequestHeaders returns a map of consolidated HTTP headers defined as
+// part of a client build or a client request. All values of repeated headers
+// are kept. If a header has been defined in both, the header merge policy of
+// the client build decides whether the request values replace or are appended
+// to the build values. Any headers named in remove are deleted for this
+// request only. It also ensures a name for the user agent is set.
+func (c *client) joinRequestHeaders(headers http.Header, remove ...string) http.Header {
 	h := make(http.Header)
 
 	// Set client build headers.
-	for key, value := range c.builder.headers {
-		h.Set(key, value[0])
+	for key, values := range c.builder.headers {
+		for _, value := range values {
+			h.Add(key, value)
+		}
 	}
 
 	// Set client request headers. If a header is also defined as part of a
-	// client build, its value will be replaced with the one beneath.
-	for key, value := range headers {
-		h.Set(key, value[0])
+	// client build, its values will be replaced with or followed by the ones
+	// beneath.
+	for key, values := range headers {
+		if c.builder.headerMergePolicy == HeaderReplace {
+			h.Del(key)
+		}
+		for _, value := range values {
+			h.Add(key, value)
+		}
+	}
+
+	// Delete headers excluded from this client request.
+	for _, key := range remove {
+		h.Del(key)
 	}
 
 	// Set the name of the user agent. The default value is used if User-Agent
@@ -82,3 +82,77 @@ func TestJoinHeaders(t *testing.T) {
 		})
 	}
 }
+
+func TestJoinHeadersValues(t *testing.T) {
+	buildHeaders := http.Header{
+		HeaderAccept:      {"application/json", "text/plain"},
+		"X-Forwarded-For": {"10.0.0.1"},
+		"X-Build":         {"foobar"},
+	}
+	tt := []struct {
+		name    string
+		policy  HeaderMergePolicy
+		headers http.Header
+		remove  []string
+		expect  http.Header
+	}{
+		{
+			name:    "BuildValues",
+			policy:  HeaderReplace,
+			headers: nil,
+			expect: http.Header{
+				HeaderAccept:      {"application/json", "text/plain"},
+				"X-Forwarded-For": {"10.0.0.1"},
+				"X-Build":         {"foobar"},
+			},
+		},
+		{
+			name:   "Replace",
+			policy: HeaderReplace,
+			headers: http.Header{
+				"x-forwarded-for": {"10.0.0.2", "10.0.0.3"},
+			},
+			expect: http.Header{
+				HeaderAccept:      {"application/json", "text/plain"},
+				"X-Forwarded-For": {"10.0.0.2", "10.0.0.3"},
+				"X-Build":         {"foobar"},
+			},
+		},
+		{
+			name:   "Append",
+			policy: HeaderAppend,
+			headers: http.Header{
+				"X-Forwarded-For": {"10.0.0.2", "10.0.0.3"},
+			},
+			expect: http.Header{
+				HeaderAccept:      {"application/json", "text/plain"},
+				"X-Forwarded-For": {"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+				"X-Build":         {"foobar"},
+			},
+		},
+		{
+			name:    "Remove",
+			policy:  HeaderReplace,
+			headers: nil,
+			remove:  []string{"x-build", HeaderAccept},
+			expect: http.Header{
+				"X-Forwarded-For": {"10.0.0.1"},
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &client{builder: &builder{headers: buildHeaders, headerMergePolicy: tc.policy}}
+			have := c.joinRequestHeaders(tc.headers, tc.remove...)
+			tc.expect.Set(HeaderUserAgent, DefaultUserAgent)
+			assert.Equal(t, tc.expect, have)
+		})
+	}
+}
+
+func TestGetMediaType(t *testing.T) {
+	assert.Equal(t, "application/json", getMediaType("Application/JSON; charset=utf-8"))
+	assert.Equal(t, "application/x-www-form-urlencoded", getMediaType(ContentTypeForm))
+	assert.Equal(t, "", getMediaType(""))
+	assert.Equal(t, "text/plain; =x", getMediaType(" Text/Plain; =x"))
+}
@@ -0,0 +1,59 @@
+package goclient
+
+import (
+	"errors"
+	"net/http"
+)
+
+// errNoResponse is returned when a middleware returns neither a response nor
+// an error.
+var errNoResponse = errors.New("goclient: middleware returned no response")
+
+// RoundTripFunc performs a single HTTP round trip. The response body is owned
+// by the caller.
+type RoundTripFunc func(request *http.Request) (*http.Response, error)
+
+// Middleware wraps a round trip to handle cross-cutting concerns such as
+// authentication, logging, metrics or signing. A middleware may modify the
+// outgoing request before calling next, and may inspect or replace the
+// resulting response or error.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// roundTrip sends the request through the middleware chain defined as part of
+// the client build. The first middleware is the outermost one, so it sees the
+// request first and the response last.
+func (c *client) roundTrip(request *http.Request, opts *requestOptions) (*http.Response, error) {
+	httpClient := c.getClient()
+	if opts.stream {
+		// The overall timeout includes reading the response body, which
+		// would cut off long-running streams.
+		streamClient := *httpClient
+		streamClient.Timeout = 0
+		httpClient = &streamClient
+	}
+
+	next := RoundTripFunc(httpClient.Do)
+	for i := len(c.builder.middleware) - 1; i >= 0; i-- {
+		next = c.builder.middleware[i](next)
+	}
+
+	response, err := next(request)
+	if response == nil && err == nil {
+		return nil, errNoResponse
+	}
+	// A middleware may return a response along with an error, whose body is
+	// closed here since the rest of the client discards the response.
+	if err != nil {
+		if response != nil && response.Body != nil {
+			response.Body.Close()
+		}
+		return nil, err
+	}
+
+	// A response synthesized by a middleware may not have a body, which the
+	// rest of the client expects to read and close.
+	if response.Body == nil {
+		response.Body = http.NoBody
+	}
+	return response, nil
+}
@@ -0,0 +1,124 @@
+package goclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get(HeaderAuthorization))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	t.Run("Order", func(t *testing.T) {
+		var calls []string
+		trace := func(name string) Middleware {
+			return func(next RoundTripFunc) RoundTripFunc {
+				return func(request *http.Request) (*http.Response, error) {
+					calls = append(calls, name+":request")
+					response, err := next(request)
+					calls = append(calls, name+":response")
+					return response, err
+				}
+			}
+		}
+		auth := func(next RoundTripFunc) RoundTripFunc {
+			return func(request *http.Request) (*http.Response, error) {
+				request.Header.Set(HeaderAuthorization, "Bearer token")
+				return next(request)
+			}
+		}
+
+		c := &client{builder: &builder{baseURL: s.URL}}
+		c.builder.Use(trace("first"), trace("second"), auth)
+		response, err := c.doRequest(context.Background(), http.MethodGet, "/api", nil)
+		require.NoError(t, err, "expected no errors")
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, []string{
+			"first:request",
+			"second:request",
+			"second:response",
+			"first:response",
+		}, calls)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		var seen error
+		observe := func(next RoundTripFunc) RoundTripFunc {
+			return func(request *http.Request) (*http.Response, error) {
+				response, err := next(request)
+				seen = err
+				return response, err
+			}
+		}
+		fail := func(next RoundTripFunc) RoundTripFunc {
+			return func(request *http.Request) (*http.Response, error) {
+				return nil, errors.New("signing failed")
+			}
+		}
+
+		c := &client{builder: &builder{baseURL: s.URL}}
+		c.builder.Use(observe, fail)
+		response, err := c.doRequest(context.Background(), http.MethodGet, "/api", nil)
+		assert.EqualError(t, err, "signing failed")
+		assert.Equal(t, err, seen)
+		assert.Empty(t, response, "response should be nil")
+	})
+
+	t.Run("NoResponse", func(t *testing.T) {
+		empty := func(next RoundTripFunc) RoundTripFunc {
+			return func(request *http.Request) (*http.Response, error) {
+				return nil, nil
+			}
+		}
+
+		c := &client{builder: &builder{baseURL: s.URL}}
+		c.builder.Use(empty)
+		response, err := c.doRequest(context.Background(), http.MethodGet, "/api", nil)
+		assert.ErrorIs(t, err, errNoResponse)
+		assert.Empty(t, response, "response should be nil")
+	})
+
+	t.Run("ResponseWithError", func(t *testing.T) {
+		body := &mockReadCloser{Reader: strings.NewReader("foobar")}
+		fail := func(next RoundTripFunc) RoundTripFunc {
+			return func(request *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: body}, errors.New("signing failed")
+			}
+		}
+
+		c := &client{builder: &builder{baseURL: s.URL}}
+		c.builder.Use(fail)
+		response, err := c.doRequest(context.Background(), http.MethodGet, "/api", nil)
+		assert.EqualError(t, err, "signing failed")
+		assert.Empty(t, response, "response should be nil")
+		assert.True(t, body.closed, "response body should be closed")
+	})
+
+	t.Run("NilBody", func(t *testing.T) {
+		synthesize := func(next RoundTripFunc) RoundTripFunc {
+			return func(request *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusNoContent, Header: http.Header{}}, nil
+			}
+		}
+
+		for _, options := range [][]RequestOption{nil, {WithStream()}} {
+			c := &client{builder: &builder{baseURL: s.URL}}
+			c.builder.Use(synthesize)
+			response, err := c.doRequest(context.Background(), http.MethodGet, "/api", nil, options...)
+			require.NoError(t, err, "expected no errors")
+			assert.Equal(t, http.StatusNoContent, response.StatusCode)
+			assert.Empty(t, response.Body)
+			assert.NoError(t, response.Close())
+		}
+	})
+}
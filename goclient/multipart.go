@@ -0,0 +1,175 @@
+package goclient
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// defaultFileContentType is the content type of a file part if one is not
+// specified.
+const defaultFileContentType = "application/octet-stream"
+
+// Multipart represents a multipart/form-data request body. It is streamed to
+// the web service, so files are never held in memory as a whole. The
+// Content-Type header, including the boundary, is set automatically.
+type Multipart struct {
+	// Fields are the form fields, which are written in key order before any
+	// files.
+	Fields url.Values
+
+	// Files are the file parts, which are written in order.
+	Files []FilePart
+}
+
+// FilePart represents a file of a multipart/form-data request body. The file
+// content is read from Reader or, if Reader is nil, from the file at Path.
+type FilePart struct {
+	FieldName string
+
+	// FileName is the name of the file sent to the web service. Defaults to
+	// the base name of Path.
+	FileName string
+
+	// ContentType is the content type of the file. Defaults to
+	// application/octet-stream.
+	ContentType string
+
+	// Reader provides the file content. It is read once and is not closed.
+	Reader io.Reader
+
+	// Path is the path of the file to read if Reader is nil. The file is
+	// opened when the request body is sent and closed once it is read.
+	Path string
+}
+
+// quoteEscaper escapes the values of a Content-Disposition header.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// multipartBody streams a multipart/form-data request body through a pipe.
+// The goroutine writing to the pipe is only started once the body is read, so
+// a request that is never sent does not leak it.
+type multipartBody struct {
+	multipart *Multipart
+	boundary  string
+	startOnce sync.Once
+	reader    *io.PipeReader
+	writer    *io.PipeWriter
+}
+
+// newMultipartBody returns a multipart/form-data request body that uses the
+// given boundary.
+func newMultipartBody(m *Multipart, boundary string) *multipartBody {
+	reader, writer := io.Pipe()
+	return &multipartBody{
+		multipart: m,
+		boundary:  boundary,
+		reader:    reader,
+		writer:    writer,
+	}
+}
+
+// contentType returns the Content-Type header value of the request body.
+func (b *multipartBody) contentType() string {
+	return "multipart/form-data; boundary=" + b.boundary
+}
+
+// replayable reports whether the request body can be sent more than once,
+// which is the case if all files are read from a path.
+func (b *multipartBody) replayable() bool {
+	for _, file := range b.multipart.Files {
+		if file.Reader != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// getBody returns a fresh copy of the request body for retries.
+func (b *multipartBody) getBody() (io.ReadCloser, error) {
+	return newMultipartBody(b.multipart, b.boundary), nil
+}
+
+// Read reads the encoded request body, starting to write it on first use.
+func (b *multipartBody) Read(p []byte) (int, error) {
+	b.startOnce.Do(func() {
+		go func() {
+			b.writer.CloseWithError(b.write())
+		}()
+	})
+	return b.reader.Read(p)
+}
+
+// Close closes the request body, which stops the goroutine writing to it.
+func (b *multipartBody) Close() error {
+	return b.reader.Close()
+}
+
+// write encodes the fields and files of the request body to the pipe.
+func (b *multipartBody) write() error {
+	w := multipart.NewWriter(b.writer)
+	if err := w.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(b.multipart.Fields))
+	for key := range b.multipart.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range b.multipart.Fields[key] {
+			if err := w.WriteField(key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, file := range b.multipart.Files {
+		if err := writeFilePart(w, file); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// writeFilePart writes a single file part to the multipart writer.
+func writeFilePart(w *multipart.Writer, file FilePart) error {
+	reader := file.Reader
+	if reader == nil {
+		f, err := os.Open(file.Path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	fileName := file.FileName
+	if fileName == "" && file.Path != "" {
+		fileName = filepath.Base(file.Path)
+	}
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = defaultFileContentType
+	}
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(file.FieldName), quoteEscaper.Replace(fileName)))
+	h.Set(HeaderContentType, contentType)
+
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, reader)
+	return err
+}
@@ -0,0 +1,163 @@
+package goclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockPart holds a part of a multipart/form-data request body received by a
+// mock server.
+type mockPart struct {
+	FormName    string
+	FileName    string
+	ContentType string
+	Content     string
+}
+
+func readParts(t *testing.T, r *http.Request) []mockPart {
+	reader, err := r.MultipartReader()
+	require.NoError(t, err, "expected no errors")
+
+	var parts []mockPart
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		require.NoError(t, err, "expected no errors")
+
+		content, err := io.ReadAll(part)
+		require.NoError(t, err, "expected no errors")
+		parts = append(parts, mockPart{
+			FormName:    part.FormName(),
+			FileName:    part.FileName(),
+			ContentType: part.Header.Get(HeaderContentType),
+			Content:     string(content),
+		})
+	}
+}
+
+func TestMultipartBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	require.NoError(t, os.WriteFile(path, []byte("a,b\n1,2\n"), 0o600))
+
+	var received []mockPart
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasPrefix(r.Header.Get(HeaderContentType), "multipart/form-data; boundary="))
+		received = readParts(t, r)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	body := Multipart{
+		Fields: url.Values{"title": {"Report"}, "author": {"foo", "bar"}},
+		Files: []FilePart{
+			{FieldName: "notes", FileName: "notes.txt", ContentType: "text/plain", Reader: strings.NewReader("foobar")},
+			{FieldName: "report", Path: path},
+		},
+	}
+	headers := http.Header{HeaderContentType: {ContentTypeJson}}
+
+	c := &client{builder: &builder{baseURL: s.URL}}
+	response, err := c.doRequest(context.Background(), http.MethodPost, "/upload", body, WithHeaders(headers))
+	require.NoError(t, err, "expected no errors")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []mockPart{
+		{FormName: "author", Content: "foo"},
+		{FormName: "author", Content: "bar"},
+		{FormName: "title", Content: "Report"},
+		{FormName: "notes", FileName: "notes.txt", ContentType: "text/plain", Content: "foobar"},
+		{FormName: "report", FileName: "report.csv", ContentType: "application/octet-stream", Content: "a,b\n1,2\n"},
+	}, received)
+}
+
+func TestMultipartBodyRetry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	require.NoError(t, os.WriteFile(path, []byte("a,b\n1,2\n"), 0o600))
+
+	tt := []struct {
+		name     string
+		file     FilePart
+		expect   int
+		attempts int32
+	}{
+		{
+			name:     "Replayable",
+			file:     FilePart{FieldName: "report", Path: path},
+			expect:   http.StatusOK,
+			attempts: 2,
+		},
+		{
+			name:     "NotReplayable",
+			file:     FilePart{FieldName: "report", FileName: "report.csv", Reader: strings.NewReader("a,b\n1,2\n")},
+			expect:   http.StatusServiceUnavailable,
+			attempts: 1,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts int32
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				parts := readParts(t, r)
+				require.Len(t, parts, 1)
+				assert.Equal(t, "a,b\n1,2\n", parts[0].Content)
+
+				if atomic.AddInt32(&attempts, 1) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer s.Close()
+
+			c := &client{builder: &builder{
+				baseURL: s.URL,
+				retryPolicy: &RetryPolicy{
+					MaxAttempts:        2,
+					InitialBackoff:     time.Millisecond,
+					RetryNonIdempotent: true,
+				},
+			}}
+			body := &Multipart{Files: []FilePart{tc.file}}
+			response, err := c.doRequest(context.Background(), http.MethodPost, "/upload", body)
+			require.NoError(t, err, "expected no errors")
+			assert.Equal(t, tc.expect, response.StatusCode)
+			assert.Equal(t, tc.attempts, atomic.LoadInt32(&attempts))
+		})
+	}
+}
+
+func TestMultipartBodyError(t *testing.T) {
+	t.Run("MissingFile", func(t *testing.T) {
+		mb := newMultipartBody(&Multipart{
+			Files: []FilePart{{FieldName: "report", Path: filepath.Join(t.TempDir(), "missing.csv")}},
+		}, "foobar")
+		_, err := io.ReadAll(mb)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("InvalidBoundary", func(t *testing.T) {
+		mb := newMultipartBody(&Multipart{}, "")
+		_, err := io.ReadAll(mb)
+		assert.Error(t, err)
+	})
+
+	t.Run("ClosedBeforeRead", func(t *testing.T) {
+		mb := newMultipartBody(&Multipart{}, "foobar")
+		require.NoError(t, mb.Close())
+		_, err := mb.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+	})
+}
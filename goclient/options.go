@@ -0,0 +1,109 @@
+package goclient
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// RequestOption configures a single client request. Request options take
+// precedence over the settings defined as part of the client build.
+type RequestOption func(*requestOptions)
+
+// requestOptions holds the settings of a single client request.
+type requestOptions struct {
+	headers             http.Header
+	removeHeaders       []string
+	stream              bool
+	maxResponseBodySize int64
+	compression         *Compression
+	query               []any
+	pathParams          map[string]string
+
+	// decompress is set if the client advertised the content encodings it
+	// decodes, in which case the response body is decoded.
+	decompress bool
+}
+
+// newRequestOptions returns the settings of a client request with the options
+// applied in order.
+func newRequestOptions(options ...RequestOption) *requestOptions {
+	opts := &requestOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+	return opts
+}
+
+// WithHeaders sets request headers defined as part of a client request.
+func WithHeaders(headers http.Header) RequestOption {
+	return func(o *requestOptions) {
+		o.headers = headers
+	}
+}
+
+// WithoutHeaders deletes the named headers defined as part of the client build
+// for this request only.
+func WithoutHeaders(names ...string) RequestOption {
+	return func(o *requestOptions) {
+		o.removeHeaders = append(o.removeHeaders, names...)
+	}
+}
+
+// WithStream sets the response body to be streamed rather than read in full.
+// The unread body is available as Response.Stream and must be closed by the
+// calling application.
+//
+// The overall timeout of the client does not apply to streamed requests, so
+// the body can be read for as long as needed. The connection and response
+// timeouts still apply, and the request context can be used to abandon it.
+func WithStream() RequestOption {
+	return func(o *requestOptions) {
+		o.stream = true
+	}
+}
+
+// WithMaxResponseBodySize overrides the max size of the response body in bytes
+// defined as part of the client build. A negative value means no limit.
+func WithMaxResponseBodySize(size int64) RequestOption {
+	return func(o *requestOptions) {
+		o.maxResponseBodySize = size
+	}
+}
+
+// WithCompression overrides how the request body is compressed. An empty
+// encoding sends the request body uncompressed.
+func WithCompression(compression Compression) RequestOption {
+	return func(o *requestOptions) {
+		o.compression = &compression
+	}
+}
+
+// WithQuery adds query parameters to the request URL, after any that are part
+// of the endpoint. The query must be url.Values, a map of strings or a struct
+// whose fields are named by their url tag, in which case slices are repeated
+// for each element and time.Time values are formatted as RFC 3339 unless the
+// unix, unixmilli or layout tag says otherwise. WithQuery can be used more
+// than once.
+func WithQuery(query any) RequestOption {
+	return func(o *requestOptions) {
+		o.query = append(o.query, query)
+	}
+}
+
+// WithPathParam sets the value of a {name} placeholder in the endpoint, such as
+// /students/{id}. The value is formatted like a query parameter and escaped as
+// a single path segment, so a value containing "/" or a dot segment such as
+// ".." cannot change the path. An empty value fails the request.
+func WithPathParam(name string, value any) RequestOption {
+	return func(o *requestOptions) {
+		if o.pathParams == nil {
+			o.pathParams = make(map[string]string)
+		}
+		s, err := formatValue(reflect.ValueOf(value))
+		if err != nil {
+			s = fmt.Sprint(value)
+		}
+		o.pathParams[name] = s
+	}
+}
@@ -0,0 +1,49 @@
+package goclient
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRequestOptions(t *testing.T) {
+	have := newRequestOptions()
+	assert.Equal(t, &requestOptions{}, have)
+}
+
+func TestWithHeaders(t *testing.T) {
+	have := newRequestOptions(WithHeaders(http.Header{HeaderContentType: {ContentTypeJson}}))
+	assert.Equal(t, "application/json", have.headers.Get(HeaderContentType))
+}
+
+func TestWithStream(t *testing.T) {
+	have := newRequestOptions(WithStream())
+	assert.True(t, have.stream)
+}
+
+func TestWithMaxResponseBodySize(t *testing.T) {
+	have := newRequestOptions(WithMaxResponseBodySize(512))
+	assert.Equal(t, int64(512), have.maxResponseBodySize)
+}
+
+func TestWithoutHeaders(t *testing.T) {
+	have := newRequestOptions(WithoutHeaders(HeaderAuthorization), WithoutHeaders(HeaderAccept))
+	assert.Equal(t, []string{HeaderAuthorization, HeaderAccept}, have.removeHeaders)
+}
+
+func TestWithCompression(t *testing.T) {
+	have := newRequestOptions(WithCompression(Compression{Encoding: EncodingZstd}))
+	assert.Equal(t, &Compression{Encoding: EncodingZstd}, have.compression)
+}
+
+func TestWithQuery(t *testing.T) {
+	have := newRequestOptions(WithQuery(url.Values{"id": {"1"}}), WithQuery(map[string]string{"page": "2"}))
+	assert.Equal(t, []any{url.Values{"id": {"1"}}, map[string]string{"page": "2"}}, have.query)
+}
+
+func TestWithPathParam(t *testing.T) {
+	have := newRequestOptions(WithPathParam("id", 42), WithPathParam("name", "foo bar"))
+	assert.Equal(t, map[string]string{"id": "42", "name": "foo bar"}, have.pathParams)
+}
@@ -0,0 +1,91 @@
+package goclient
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"strings"
+)
+
+// CertificatePins defines the public keys that web services must present, in
+// addition to the verification of their certificate chain. A pin is the base64
+// SHA-256 hash of the SubjectPublicKeyInfo of a certificate, optionally with a
+// sha256/ prefix, and may pin the leaf, an intermediate or a root certificate.
+type CertificatePins struct {
+	// Hosts maps the server name of a web service to its pins. The server
+	// name is the host of the request URL, unless it is overridden by the
+	// ServerName of the TLS config. Hosts without pins are not checked.
+	Hosts map[string][]string
+
+	// ReportOnly lets connections through despite a pin mismatch, which is
+	// only passed to OnMismatch. It allows pins to be rolled out safely.
+	ReportOnly bool
+
+	// OnMismatch is called with every pin mismatch, whether or not it fails
+	// the connection.
+	OnMismatch func(err *ErrPinMismatch)
+}
+
+// chainVerifier verifies the certificate chain of a web service and returns
+// the verified chains.
+type chainVerifier func(cs tls.ConnectionState) ([][]*x509.Certificate, error)
+
+// spkiHash returns the base64 SHA-256 hash of the SubjectPublicKeyInfo of cert.
+func spkiHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// verifyConnection returns a VerifyConnection callback that checks the pins of
+// a web service. Its certificate chain is first verified by verify, or by the
+// standard library if verify is nil. Only the verified chains are checked, so
+// a pinned certificate that does not chain to the leaf cannot be slipped in.
+func (p *CertificatePins) verifyConnection(verify chainVerifier) func(cs tls.ConnectionState) error {
+	pins := make(map[string]map[string]bool, len(p.Hosts))
+	for host, hashes := range p.Hosts {
+		host = strings.ToLower(host)
+		pins[host] = make(map[string]bool, len(hashes))
+		for _, hash := range hashes {
+			pins[host][strings.TrimPrefix(hash, "sha256/")] = true
+		}
+	}
+
+	return func(cs tls.ConnectionState) error {
+		chains := cs.VerifiedChains
+		if verify != nil {
+			var err error
+			if chains, err = verify(cs); err != nil {
+				return err
+			}
+		}
+
+		hostPins := pins[strings.ToLower(cs.ServerName)]
+		if len(hostPins) == 0 {
+			return nil
+		}
+		var presented []string
+		seen := make(map[string]bool)
+		for _, chain := range chains {
+			for _, cert := range chain {
+				hash := spkiHash(cert)
+				if hostPins[hash] {
+					return nil
+				}
+				if !seen[hash] {
+					seen[hash] = true
+					presented = append(presented, hash)
+				}
+			}
+		}
+
+		err := &ErrPinMismatch{Host: cs.ServerName, Presented: presented}
+		if p.OnMismatch != nil {
+			p.OnMismatch(err)
+		}
+		if p.ReportOnly {
+			return nil
+		}
+		return err
+	}
+}
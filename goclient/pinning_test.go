@@ -0,0 +1,211 @@
+package goclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpkiHash(t *testing.T) {
+	cert, _, _ := mockCert(t, t.TempDir(), "foobar.com")
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), spkiHash(cert))
+}
+
+func TestCertificatePinsVerifyConnection(t *testing.T) {
+	dir := t.TempDir()
+	leaf, _, _ := mockCert(t, dir, "leaf")
+	root, _, _ := mockCert(t, dir, "root")
+	other, _, _ := mockCert(t, dir, "other")
+	chain := [][]*x509.Certificate{{leaf, root}}
+	verifyErr := errors.New("unknown authority")
+
+	tt := []struct {
+		name       string
+		pins       CertificatePins
+		verify     chainVerifier
+		state      tls.ConnectionState
+		expect     []string
+		mismatches int
+		hasError   bool
+	}{
+		{
+			name:  "NoPinsForHost",
+			pins:  CertificatePins{Hosts: map[string][]string{"barfoo.com": {spkiHash(other)}}},
+			state: tls.ConnectionState{ServerName: "foobar.com", VerifiedChains: chain},
+		},
+		{
+			name:  "LeafPin",
+			pins:  CertificatePins{Hosts: map[string][]string{"foobar.com": {spkiHash(other), spkiHash(leaf)}}},
+			state: tls.ConnectionState{ServerName: "foobar.com", VerifiedChains: chain},
+		},
+		{
+			name:  "RootPinWithPrefix",
+			pins:  CertificatePins{Hosts: map[string][]string{"FooBar.com": {"sha256/" + spkiHash(root)}}},
+			state: tls.ConnectionState{ServerName: "foobar.com", VerifiedChains: chain},
+		},
+		{
+			name:       "Mismatch",
+			pins:       CertificatePins{Hosts: map[string][]string{"foobar.com": {spkiHash(other)}}},
+			state:      tls.ConnectionState{ServerName: "foobar.com", VerifiedChains: append(chain, chain...)},
+			expect:     []string{spkiHash(leaf), spkiHash(root)},
+			mismatches: 1,
+			hasError:   true,
+		},
+		{
+			name:       "ReportOnly",
+			pins:       CertificatePins{Hosts: map[string][]string{"foobar.com": {spkiHash(other)}}, ReportOnly: true},
+			state:      tls.ConnectionState{ServerName: "foobar.com", VerifiedChains: chain},
+			mismatches: 1,
+		},
+		{
+			name:       "UnverifiedPeerCertificate",
+			pins:       CertificatePins{Hosts: map[string][]string{"foobar.com": {spkiHash(other)}}},
+			state:      tls.ConnectionState{ServerName: "foobar.com", PeerCertificates: []*x509.Certificate{leaf, other}, VerifiedChains: chain},
+			expect:     []string{spkiHash(leaf), spkiHash(root)},
+			mismatches: 1,
+			hasError:   true,
+		},
+		{
+			name: "CustomVerifier",
+			pins: CertificatePins{Hosts: map[string][]string{"foobar.com": {spkiHash(root)}}},
+			verify: func(tls.ConnectionState) ([][]*x509.Certificate, error) {
+				return chain, nil
+			},
+			state: tls.ConnectionState{ServerName: "foobar.com"},
+		},
+		{
+			name: "CustomVerifierError",
+			pins: CertificatePins{Hosts: map[string][]string{"foobar.com": {spkiHash(root)}}},
+			verify: func(tls.ConnectionState) ([][]*x509.Certificate, error) {
+				return nil, verifyErr
+			},
+			state:    tls.ConnectionState{ServerName: "foobar.com"},
+			hasError: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			mismatches := 0
+			tc.pins.OnMismatch = func(*ErrPinMismatch) { mismatches++ }
+			err := tc.pins.verifyConnection(tc.verify)(tc.state)
+			assert.Equal(t, tc.mismatches, mismatches)
+			if !tc.hasError {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			if tc.expect == nil {
+				assert.ErrorIs(t, err, verifyErr)
+				return
+			}
+			var pinErr *ErrPinMismatch
+			require.ErrorAs(t, err, &pinErr)
+			assert.Equal(t, "foobar.com", pinErr.Host)
+			assert.Equal(t, tc.expect, pinErr.Presented)
+		})
+	}
+}
+
+func TestDoRequestCertificatePins(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, certFile, keyFile := mockCert(t, dir, "server", net.IPv4(127, 0, 0, 1))
+	other, _, _ := mockCert(t, dir, "other")
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+	var attempts atomic.Int32
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	s.TLS = &tls.Config{Certificates: []tls.Certificate{pair}}
+	s.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			attempts.Add(1)
+		}
+	}
+	s.StartTLS()
+	defer s.Close()
+	s.Config.SetKeepAlivesEnabled(false)
+
+	tt := []struct {
+		name       string
+		tlsConfig  TLSConfig
+		pins       CertificatePins
+		mismatches int
+		hasError   bool
+	}{
+		{
+			name:      "Match",
+			tlsConfig: TLSConfig{RootCAFiles: []string{certFile}},
+			pins:      CertificatePins{Hosts: map[string][]string{"127.0.0.1": {spkiHash(serverCert)}}},
+		},
+		{
+			name:       "Mismatch",
+			tlsConfig:  TLSConfig{RootCAFiles: []string{certFile}},
+			pins:       CertificatePins{Hosts: map[string][]string{"127.0.0.1": {spkiHash(other)}}},
+			mismatches: 1,
+			hasError:   true,
+		},
+		{
+			name:       "ReportOnly",
+			tlsConfig:  TLSConfig{RootCAFiles: []string{certFile}},
+			pins:       CertificatePins{Hosts: map[string][]string{"127.0.0.1": {spkiHash(other)}}, ReportOnly: true},
+			mismatches: 1,
+		},
+		{
+			name:      "ReloadMatch",
+			tlsConfig: TLSConfig{RootCAFiles: []string{certFile}, ReloadInterval: time.Minute},
+			pins:      CertificatePins{Hosts: map[string][]string{"127.0.0.1": {spkiHash(serverCert)}}},
+		},
+		{
+			name:       "ReloadMismatch",
+			tlsConfig:  TLSConfig{RootCAFiles: []string{certFile}, ReloadInterval: time.Minute},
+			pins:       CertificatePins{Hosts: map[string][]string{"127.0.0.1": {spkiHash(other)}}},
+			mismatches: 1,
+			hasError:   true,
+		},
+		{
+			name:      "UntrustedChain",
+			tlsConfig: TLSConfig{RootCAFiles: []string{filepath.Join(dir, "other.crt")}},
+			pins:      CertificatePins{Hosts: map[string][]string{"127.0.0.1": {spkiHash(serverCert)}}},
+			hasError:  true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var mismatches []*ErrPinMismatch
+			tc.pins.OnMismatch = func(err *ErrPinMismatch) { mismatches = append(mismatches, err) }
+			attempts.Store(0)
+			c := NewBuild().
+				SetBaseURL(s.URL).
+				SetTLSConfig(tc.tlsConfig).
+				SetCertificatePins(tc.pins).
+				SetRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}).
+				Build()
+			_, err := c.Do(context.Background(), http.MethodGet, "/", nil)
+			assert.Len(t, mismatches, tc.mismatches)
+			if !tc.hasError {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			if tc.mismatches > 0 {
+				var pinErr *ErrPinMismatch
+				require.ErrorAs(t, err, &pinErr)
+				assert.Equal(t, []string{spkiHash(serverCert)}, pinErr.Presented)
+				assert.Equal(t, int32(1), attempts.Load(), "pin mismatches should not be retried")
+			}
+		})
+	}
+}
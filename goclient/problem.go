@@ -0,0 +1,74 @@
+package goclient
+
+import (
+	"encoding/json"
+)
+
+// problemTypeBlank is the problem type assumed when a problem details object
+// has no type member.
+const problemTypeBlank = "about:blank"
+
+// Problem represents a problem details object as defined by RFC 9457. It is
+// returned by web services as an application/problem+json response body.
+type Problem struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+
+	// Extensions holds any members other than the standard ones. Their values
+	// are kept as raw JSON so they can be decoded into the desired type.
+	Extensions map[string]json.RawMessage
+}
+
+// UnmarshalJSON decodes a problem details object. As required by RFC 9457,
+// standard members with a value of the wrong type are ignored rather than
+// failing the entire object.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	*p = Problem{Type: problemTypeBlank}
+	for name, value := range members {
+		// Decoding errors of the standard members are ignored, leaving the
+		// zero value in place.
+		switch name {
+		case "type":
+			_ = json.Unmarshal(value, &p.Type)
+		case "title":
+			_ = json.Unmarshal(value, &p.Title)
+		case "status":
+			_ = json.Unmarshal(value, &p.Status)
+		case "detail":
+			_ = json.Unmarshal(value, &p.Detail)
+		case "instance":
+			_ = json.Unmarshal(value, &p.Instance)
+		default:
+			if p.Extensions == nil {
+				p.Extensions = make(map[string]json.RawMessage)
+			}
+			p.Extensions[name] = value
+		}
+	}
+	if p.Type == "" {
+		p.Type = problemTypeBlank
+	}
+	return nil
+}
+
+// Problem returns the problem details object of the response body. A nil
+// problem is returned if the response is not application/problem+json.
+func (r *Response) Problem() (*Problem, error) {
+	if getMediaType(r.ResponseHeaders.Get(HeaderContentType)) != ContentTypeProblemJson {
+		return nil, nil
+	}
+
+	var problem Problem
+	if err := json.Unmarshal(r.BytesBody(), &problem); err != nil {
+		return nil, err
+	}
+	return &problem, nil
+}
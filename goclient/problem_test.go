@@ -0,0 +1,115 @@
+package goclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemUnmarshalJSON(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     string
+		expect   Problem
+		hasError bool
+	}{
+		{
+			name: "AllMembers",
+			data: `{
+				"type": "https://example.com/probs/out-of-credit",
+				"title": "You do not have enough credit.",
+				"status": 403,
+				"detail": "Your current balance is 30, but that costs 50.",
+				"instance": "/account/12345/msgs/abc",
+				"balance": 30,
+				"accounts": ["/account/12345", "/account/67890"]
+			}`,
+			expect: Problem{
+				Type:     "https://example.com/probs/out-of-credit",
+				Title:    "You do not have enough credit.",
+				Status:   403,
+				Detail:   "Your current balance is 30, but that costs 50.",
+				Instance: "/account/12345/msgs/abc",
+				Extensions: map[string]json.RawMessage{
+					"balance":  json.RawMessage(`30`),
+					"accounts": json.RawMessage(`["/account/12345", "/account/67890"]`),
+				},
+			},
+		},
+		{
+			name:   "DefaultType",
+			data:   `{"title": "Not Found", "status": 404}`,
+			expect: Problem{Type: "about:blank", Title: "Not Found", Status: 404},
+		},
+		{
+			name:   "WrongMemberType",
+			data:   `{"type": 1, "title": "Not Found", "status": "404"}`,
+			expect: Problem{Type: "about:blank", Title: "Not Found"},
+		},
+		{
+			name:     "NotAnObject",
+			data:     `[]`,
+			hasError: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var have Problem
+			err := json.Unmarshal([]byte(tc.data), &have)
+			if tc.hasError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err, "expected no errors")
+			assert.Equal(t, tc.expect, have)
+		})
+	}
+}
+
+func TestResponseProblem(t *testing.T) {
+	tt := []struct {
+		name        string
+		contentType string
+		body        string
+		expect      *Problem
+		hasError    bool
+	}{
+		{
+			name:        "ProblemJson",
+			contentType: "application/problem+json; charset=utf-8",
+			body:        `{"title": "Not Found", "status": 404}`,
+			expect:      &Problem{Type: "about:blank", Title: "Not Found", Status: 404},
+		},
+		{
+			name:        "OtherContentType",
+			contentType: ContentTypeJson,
+			body:        `{"title": "Not Found", "status": 404}`,
+			expect:      nil,
+		},
+		{
+			name:        "MalformedBody",
+			contentType: ContentTypeProblemJson,
+			body:        `{`,
+			hasError:    true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Response{
+				Body:            []byte(tc.body),
+				ResponseHeaders: http.Header{HeaderContentType: {tc.contentType}},
+			}
+			have, err := r.Problem()
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Nil(t, have)
+				return
+			}
+			require.NoError(t, err, "expected no errors")
+			assert.Equal(t, tc.expect, have)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package goclient
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// expandPath replaces each {name} placeholder in the path of an endpoint with
+// the escaped value of its path parameter. The endpoint is returned unchanged
+// if it has no placeholders. An error is returned if a placeholder has no
+// value, its value is empty or it is not closed.
+func expandPath(endpoint string, params map[string]string) (string, error) {
+	// Only the path is expanded, so braces in the query or fragment of the
+	// endpoint are left as they are.
+	path, rest := endpoint, ""
+	if i := strings.IndexAny(endpoint, "?#"); i >= 0 {
+		path, rest = endpoint[:i], endpoint[i:]
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(path, '{')
+		if start < 0 {
+			b.WriteString(path)
+			break
+		}
+		end := strings.IndexByte(path[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("goclient: unclosed path parameter in %q", endpoint)
+		}
+		name := path[start+1 : start+end]
+		value, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("goclient: missing path parameter %q", name)
+		}
+		segment, err := escapePathSegment(value)
+		if err != nil {
+			return "", fmt.Errorf("goclient: path parameter %q: %w", name, err)
+		}
+		b.WriteString(path[:start])
+		b.WriteString(segment)
+		path = path[start+end+1:]
+	}
+	return b.String() + rest, nil
+}
+
+// escapePathSegment returns value escaped as a single path segment. The dot
+// segments "." and ".." are percent-encoded, since they would otherwise be
+// removed when the endpoint is resolved and change the path. A colon is
+// percent-encoded too, since in the first segment of a relative endpoint it
+// would be parsed as the end of a scheme. An empty value is rejected, since it
+// would produce an empty segment.
+func escapePathSegment(value string) (string, error) {
+	switch value {
+	case "":
+		return "", errors.New("empty value")
+	case ".":
+		return "%2E", nil
+	case "..":
+		return "%2E%2E", nil
+	}
+	return strings.ReplaceAll(url.PathEscape(value), ":", "%3A"), nil
+}
+
+// queryValues returns the query parameters of a request as URL values. The
+// query must be url.Values, a map of strings or a struct whose fields are
+// named by their url tag.
+func queryValues(query any) (url.Values, error) {
+	switch v := query.(type) {
+	case nil:
+		return url.Values{}, nil
+	case url.Values:
+		return v, nil
+	case map[string][]string:
+		return v, nil
+	case map[string]string:
+		values := make(url.Values, len(v))
+		for key, value := range v {
+			values.Set(key, value)
+		}
+		return values, nil
+	}
+	return structValues(query, "url")
+}
+
+// addQuery appends the encoded query parameters to requestURL, after any query
+// it already has.
+func addQuery(requestURL string, queries []any) (string, error) {
+	var encoded []string
+	for _, query := range queries {
+		values, err := queryValues(query)
+		if err != nil {
+			return "", err
+		}
+		if len(values) > 0 {
+			encoded = append(encoded, values.Encode())
+		}
+	}
+	if len(encoded) == 0 {
+		return requestURL, nil
+	}
+
+	fragment := ""
+	if i := strings.IndexByte(requestURL, '#'); i >= 0 {
+		requestURL, fragment = requestURL[:i], requestURL[i:]
+	}
+	separator := "?"
+	if strings.Contains(requestURL, "?") {
+		separator = "&"
+		if strings.HasSuffix(requestURL, "?") || strings.HasSuffix(requestURL, "&") {
+			separator = ""
+		}
+	}
+	return requestURL + separator + strings.Join(encoded, "&") + fragment, nil
+}
@@ -0,0 +1,303 @@
+package goclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQuery struct {
+	Name     string    `url:"name"`
+	IDs      []int     `url:"id"`
+	Page     int       `url:"page,omitempty"`
+	Since    time.Time `url:"since"`
+	Until    time.Time `url:"until,unix"`
+	Born     time.Time `url:"born" layout:"2006-01-02"`
+	Internal string    `url:"-"`
+}
+
+func TestExpandPath(t *testing.T) {
+	tt := []struct {
+		name     string
+		endpoint string
+		params   map[string]string
+		expect   string
+		hasError bool
+	}{
+		{
+			name:     "NoPlaceholders",
+			endpoint: "/students?filter={x}",
+			expect:   "/students?filter={x}",
+		},
+		{
+			name:     "NoParams",
+			endpoint: "/students/{id}",
+			hasError: true,
+		},
+		{
+			name:     "Params",
+			endpoint: "/schools/{school}/students/{id}",
+			params:   map[string]string{"school": "st mary's", "id": "42"},
+			expect:   "/schools/st%20mary%27s/students/42",
+		},
+		{
+			name:     "EscapedSlash",
+			endpoint: "/students/{id}",
+			params:   map[string]string{"id": "../admin"},
+			expect:   "/students/..%2Fadmin",
+		},
+		{
+			name:     "DotSegment",
+			endpoint: "/students/{id}/grades",
+			params:   map[string]string{"id": "."},
+			expect:   "/students/%2E/grades",
+		},
+		{
+			name:     "DotDotSegment",
+			endpoint: "/students/{id}/grades",
+			params:   map[string]string{"id": ".."},
+			expect:   "/students/%2E%2E/grades",
+		},
+		{
+			name:     "DotsInValue",
+			endpoint: "/students/{id}",
+			params:   map[string]string{"id": "..."},
+			expect:   "/students/...",
+		},
+		{
+			name:     "Colon",
+			endpoint: "{id}/x",
+			params:   map[string]string{"id": "urn:x"},
+			expect:   "urn%3Ax/x",
+		},
+		{
+			name:     "EmptyValue",
+			endpoint: "/students/{id}/grades",
+			params:   map[string]string{"id": ""},
+			hasError: true,
+		},
+		{
+			name:     "QueryUnchanged",
+			endpoint: "/students/{id}?filter={x}",
+			params:   map[string]string{"id": "1"},
+			expect:   "/students/1?filter={x}",
+		},
+		{
+			name:     "MissingParam",
+			endpoint: "/students/{id}",
+			params:   map[string]string{"name": "foobar"},
+			hasError: true,
+		},
+		{
+			name:     "UnclosedParam",
+			endpoint: "/students/{id",
+			params:   map[string]string{"id": "1"},
+			hasError: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := expandPath(tc.endpoint, tc.params)
+			if tc.hasError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, have)
+		})
+	}
+}
+
+func TestQueryValues(t *testing.T) {
+	date := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	tt := []struct {
+		name     string
+		query    any
+		expect   string
+		hasError bool
+	}{
+		{
+			name:   "Nil",
+			query:  nil,
+			expect: "",
+		},
+		{
+			name:   "Values",
+			query:  url.Values{"a": {"1", "2"}},
+			expect: "a=1&a=2",
+		},
+		{
+			name:   "MapString",
+			query:  map[string]string{"q": "a&b c"},
+			expect: "q=a%26b+c",
+		},
+		{
+			name:   "Struct",
+			query:  mockQuery{Name: "foo bar", IDs: []int{1, 2}, Since: date, Until: date, Born: date, Internal: "x"},
+			expect: "born=2023-06-01&id=1&id=2&name=foo+bar&since=2023-06-01T12%3A00%3A00Z&until=1685620800",
+		},
+		{
+			name:     "Unsupported",
+			query:    42,
+			hasError: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := queryValues(tc.query)
+			if tc.hasError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, have.Encode())
+		})
+	}
+}
+
+func TestAddQuery(t *testing.T) {
+	tt := []struct {
+		name     string
+		url      string
+		queries  []any
+		expect   string
+		hasError bool
+	}{
+		{
+			name:   "NoQuery",
+			url:    "/students",
+			expect: "/students",
+		},
+		{
+			name:    "NewQuery",
+			url:     "/students",
+			queries: []any{url.Values{"id": {"1"}}},
+			expect:  "/students?id=1",
+		},
+		{
+			name:    "ExistingQuery",
+			url:     "/students?sort=name",
+			queries: []any{url.Values{"id": {"1"}}, map[string]string{"page": "2"}},
+			expect:  "/students?sort=name&id=1&page=2",
+		},
+		{
+			name:    "Fragment",
+			url:     "/students?#top",
+			queries: []any{url.Values{"id": {"1"}}},
+			expect:  "/students?id=1#top",
+		},
+		{
+			name:     "Unsupported",
+			url:      "/students",
+			queries:  []any{42},
+			hasError: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := addQuery(tc.url, tc.queries)
+			if tc.hasError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, have)
+		})
+	}
+}
+
+func TestDoRequestQuery(t *testing.T) {
+	var requestURI string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+	}))
+	defer s.Close()
+
+	c := &client{builder: &builder{baseURL: s.URL}}
+	_, err := c.doRequest(context.Background(), http.MethodGet, "/students/{id}/grades", nil,
+		WithPathParam("id", "a/b 100%"),
+		WithQuery(url.Values{"name": {"foo&bar"}, "id": {"1", "2"}}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "/students/a%2Fb%20100%25/grades?id=1&id=2&name=foo%26bar", requestURI)
+}
+
+func TestDoRequestBaseURLPrefix(t *testing.T) {
+	var requestURI string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+	}))
+	defer s.Close()
+
+	c := NewBuild().SetBaseURL(s.URL + "/v2").Build()
+	_, err := c.Do(context.Background(), http.MethodGet, "/students/{id}", nil,
+		WithPathParam("id", "100%"),
+		WithQuery(url.Values{"page": {"2"}}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "/v2/students/100%25?page=2", requestURI)
+}
+
+func TestDoRequestPathParamDotSegments(t *testing.T) {
+	var requestURI string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+	}))
+	defer s.Close()
+
+	c := NewBuild().SetBaseURL(s.URL + "/v2").Build()
+	tt := []struct {
+		name     string
+		value    string
+		expect   string
+		hasError bool
+	}{
+		{
+			name:   "Dot",
+			value:  ".",
+			expect: "/v2/students/%2E/grades",
+		},
+		{
+			name:   "DotDot",
+			value:  "..",
+			expect: "/v2/students/%2E%2E/grades",
+		},
+		{
+			name:     "Empty",
+			value:    "",
+			hasError: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			requestURI = ""
+			_, err := c.Do(context.Background(), http.MethodGet, "/students/{id}/grades", nil, WithPathParam("id", tc.value))
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Empty(t, requestURI, "request should not be sent")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, requestURI)
+		})
+	}
+}
+
+func TestDoRequestPathParamColon(t *testing.T) {
+	var requestURI string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+	}))
+	defer s.Close()
+
+	c := NewBuild().SetBaseURL(s.URL + "/v2").Build()
+	_, err := c.Do(context.Background(), http.MethodGet, "{id}/x", nil, WithPathParam("id", "urn:x"))
+	require.NoError(t, err)
+	assert.Equal(t, "/v2/urn%3Ax/x", requestURI)
+}
@@ -0,0 +1,191 @@
+package goclient
+
+import (
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitScope defines which requests share a token bucket.
+type RateLimitScope int
+
+const (
+	// RateLimitClient shares a single token bucket between all requests.
+	RateLimitClient RateLimitScope = iota
+
+	// RateLimitHost uses a token bucket for each host.
+	RateLimitHost
+
+	// RateLimitEndpoint uses a token bucket for each endpoint prefix. Requests
+	// whose URL path matches none of the prefixes are not rate limited.
+	RateLimitEndpoint
+)
+
+// RateLimit defines a client-side rate limit based on token buckets. Each
+// request takes a token from its bucket, which is refilled at the given rate
+// up to the burst size.
+type RateLimit struct {
+	// Rate is the number of tokens added to a bucket per second.
+	Rate float64
+
+	// Burst is the max number of tokens held by a bucket. Defaults to 1.
+	Burst int
+
+	// Scope defines which requests share a token bucket. Defaults to a
+	// single bucket for the client.
+	Scope RateLimitScope
+
+	// Prefixes are the URL path prefixes used with RateLimitEndpoint. A
+	// request is matched with its longest prefix.
+	Prefixes []string
+
+	// Wait blocks a request until a token is available or its context is
+	// done. Otherwise, a request fails immediately with ErrRateLimited if its
+	// bucket is empty.
+	Wait bool
+}
+
+// tokenBucket holds the tokens of a rate limit scope.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter keeps track of the token buckets of a rate limit. It is
+// concurrent safe.
+type rateLimiter struct {
+	limit   RateLimit
+	now     func() time.Time
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// newRateLimiter returns a rate limiter with the defaults applied to any unset
+// values of the rate limit.
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.Burst <= 0 {
+		limit.Burst = 1
+	}
+	return &rateLimiter{
+		limit:   limit,
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// key returns the token bucket key of a request. False is returned if the
+// request is not rate limited.
+func (l *rateLimiter) key(request *http.Request) (string, bool) {
+	switch l.limit.Scope {
+	case RateLimitHost:
+		return request.URL.Host, true
+	case RateLimitEndpoint:
+		match := ""
+		for _, prefix := range l.limit.Prefixes {
+			if strings.HasPrefix(request.URL.Path, prefix) && len(prefix) > len(match) {
+				match = prefix
+			}
+		}
+		return match, match != ""
+	default:
+		return "", true
+	}
+}
+
+// reserve takes a token from the bucket of the given key and returns the wait
+// until the token is available. If the wait would exceed maxWait, no token is
+// taken and false is returned.
+func (l *rateLimiter) reserve(key string, maxWait time.Duration) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	burst := float64(l.limit.Burst)
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: burst, last: now}
+		l.buckets[key] = bucket
+	}
+
+	if elapsed := now.Sub(bucket.last); elapsed > 0 {
+		bucket.tokens = math.Min(burst, bucket.tokens+elapsed.Seconds()*l.limit.Rate)
+		bucket.last = now
+	}
+
+	var wait time.Duration
+	if bucket.tokens < 1 {
+		if l.limit.Rate <= 0 {
+			return 0, false
+		}
+		wait = time.Duration((1 - bucket.tokens) / l.limit.Rate * float64(time.Second))
+	}
+	if wait > maxWait {
+		return 0, false
+	}
+	bucket.tokens--
+	return wait, true
+}
+
+// cancel returns a reserved token to the bucket of the given key.
+func (l *rateLimiter) cancel(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if bucket, ok := l.buckets[key]; ok {
+		bucket.tokens = math.Min(float64(l.limit.Burst), bucket.tokens+1)
+	}
+}
+
+// wait takes a token for the request, blocking until it is available if the
+// rate limit is configured to wait. ErrRateLimited is returned if a token is
+// not available in time.
+func (l *rateLimiter) wait(request *http.Request) error {
+	key, ok := l.key(request)
+	if !ok {
+		return nil
+	}
+
+	ctx := request.Context()
+	maxWait := time.Duration(0)
+	if l.limit.Wait {
+		maxWait = time.Duration(math.MaxInt64)
+		if deadline, ok := ctx.Deadline(); ok {
+			maxWait = deadline.Sub(l.now())
+		}
+	}
+
+	wait, ok := l.reserve(key, maxWait)
+	if !ok {
+		return ErrRateLimited
+	}
+	if wait == 0 {
+		return nil
+	}
+	if err := sleepContext(ctx, wait); err != nil {
+		l.cancel(key)
+		return wrapContextError(ctx, err)
+	}
+	return nil
+}
+
+// getRateLimiter returns the rate limiter of the client or nil if a rate limit
+// is not defined as part of the client build.
+func (c *client) getRateLimiter() *rateLimiter {
+	c.limiterOnce.Do(func() {
+		if c.builder.rateLimit != nil {
+			c.limiter = newRateLimiter(*c.builder.rateLimit)
+		}
+	})
+	return c.limiter
+}
+
+// waitRateLimit takes a token for the request if a rate limit is defined as
+// part of the client build.
+func (c *client) waitRateLimit(request *http.Request) error {
+	if limiter := c.getRateLimiter(); limiter != nil {
+		return limiter.wait(request)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package goclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockLimiter(limit RateLimit) (*rateLimiter, *mockClock) {
+	clock := &mockClock{now: time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+	l := newRateLimiter(limit)
+	l.now = clock.Now
+	return l, clock
+}
+
+func TestRateLimiterKey(t *testing.T) {
+	tt := []struct {
+		name   string
+		limit  RateLimit
+		url    string
+		expect string
+		ok     bool
+	}{
+		{
+			name:   "Client",
+			limit:  RateLimit{Scope: RateLimitClient},
+			url:    "https://foobar.com/api",
+			expect: "",
+			ok:     true,
+		},
+		{
+			name:   "Host",
+			limit:  RateLimit{Scope: RateLimitHost},
+			url:    "https://foobar.com:8443/api",
+			expect: "foobar.com:8443",
+			ok:     true,
+		},
+		{
+			name:   "LongestPrefix",
+			limit:  RateLimit{Scope: RateLimitEndpoint, Prefixes: []string{"/api", "/api/students"}},
+			url:    "https://foobar.com/api/students/1",
+			expect: "/api/students",
+			ok:     true,
+		},
+		{
+			name:   "NoPrefix",
+			limit:  RateLimit{Scope: RateLimitEndpoint, Prefixes: []string{"/api"}},
+			url:    "https://foobar.com/health",
+			expect: "",
+			ok:     false,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err, "expected no errors")
+
+			have, ok := newRateLimiter(tc.limit).key(request)
+			assert.Equal(t, tc.expect, have)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestRateLimiterReserve(t *testing.T) {
+	t.Run("Burst", func(t *testing.T) {
+		l, _ := newMockLimiter(RateLimit{Rate: 1, Burst: 2})
+		for i := 0; i < 2; i++ {
+			wait, ok := l.reserve("", 0)
+			assert.True(t, ok)
+			assert.Zero(t, wait)
+		}
+		_, ok := l.reserve("", 0)
+		assert.False(t, ok, "bucket should be empty")
+	})
+
+	t.Run("Refill", func(t *testing.T) {
+		l, clock := newMockLimiter(RateLimit{Rate: 2, Burst: 1})
+		_, ok := l.reserve("", 0)
+		require.True(t, ok)
+
+		clock.now = clock.now.Add(500 * time.Millisecond)
+		wait, ok := l.reserve("", 0)
+		assert.True(t, ok)
+		assert.Zero(t, wait)
+	})
+
+	t.Run("Wait", func(t *testing.T) {
+		l, _ := newMockLimiter(RateLimit{Rate: 4, Burst: 1})
+		_, ok := l.reserve("", 0)
+		require.True(t, ok)
+
+		wait, ok := l.reserve("", time.Second)
+		assert.True(t, ok)
+		assert.Equal(t, 250*time.Millisecond, wait)
+
+		_, ok = l.reserve("", 100*time.Millisecond)
+		assert.False(t, ok, "wait should exceed max wait")
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		l, _ := newMockLimiter(RateLimit{Rate: 1, Burst: 1})
+		_, ok := l.reserve("", 0)
+		require.True(t, ok)
+		l.cancel("")
+
+		_, ok = l.reserve("", 0)
+		assert.True(t, ok, "token should be returned")
+	})
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	request, err := http.NewRequest(http.MethodGet, "https://foobar.com/api", nil)
+	require.NoError(t, err, "expected no errors")
+
+	t.Run("FailFast", func(t *testing.T) {
+		l := newRateLimiter(RateLimit{Rate: 0.001, Burst: 1})
+		assert.NoError(t, l.wait(request))
+		assert.ErrorIs(t, l.wait(request), ErrRateLimited)
+	})
+
+	t.Run("Blocks", func(t *testing.T) {
+		l := newRateLimiter(RateLimit{Rate: 20, Burst: 1, Wait: true})
+		start := time.Now()
+		assert.NoError(t, l.wait(request))
+		assert.NoError(t, l.wait(request))
+		assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		l := newRateLimiter(RateLimit{Rate: 0.001, Burst: 1, Wait: true})
+		assert.NoError(t, l.wait(request.WithContext(ctx)))
+		assert.ErrorIs(t, l.wait(request.WithContext(ctx)), ErrRateLimited)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		l := newRateLimiter(RateLimit{Rate: 1, Burst: 1, Wait: true})
+		assert.NoError(t, l.wait(request.WithContext(ctx)))
+
+		cancel()
+		assert.ErrorIs(t, l.wait(request.WithContext(ctx)), ErrRequestCancelled)
+	})
+}
+
+func TestDoAttemptRateLimit(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	c := &client{builder: &builder{
+		baseURL:   s.URL,
+		rateLimit: &RateLimit{Rate: 0.001, Burst: 1},
+	}}
+	response, err := c.doRequest(context.Background(), http.MethodGet, "/api", nil)
+	require.NoError(t, err, "expected no errors")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, err = c.doRequest(context.Background(), http.MethodGet, "/api", nil)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Empty(t, response, "response should be nil")
+}
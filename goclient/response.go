@@ -2,9 +2,19 @@ package goclient
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// maxRetryAfterSeconds is the largest number of seconds in a Retry-After header
+// that can be represented as a duration.
+const maxRetryAfterSeconds = int64(math.MaxInt64 / time.Second)
+
 // Response represents the objects returned by a web service in response to a
 // client request.
 type Response struct {
@@ -12,6 +22,31 @@ type Response struct {
 	Status          string
 	StatusCode      int
 	ResponseHeaders http.Header
+
+	// RetryAfter is the parsed value of the Retry-After header of a 429 or
+	// 503 response. It is zero if the header is absent or invalid, or asks
+	// for no wait.
+	RetryAfter time.Duration
+
+	// Stream holds the unread response body of a request made with the
+	// WithStream option, in which case Body is nil. It must be closed by the
+	// calling application.
+	Stream io.ReadCloser
+
+	// ContentEncoding is the original Content-Encoding of a response body
+	// that was decoded by the client, such as gzip, br or zstd. It is empty
+	// if the response body was not encoded.
+	ContentEncoding string
+
+	// hasRetryAfter reports whether RetryAfter was parsed from a valid header,
+	// so that a header asking for no wait is not mistaken for an absent one.
+	hasRetryAfter bool
+
+	// request is the request that the response was returned for.
+	request *http.Request
+
+	// codecs are the codecs registered as part of the client build.
+	codecs map[string]Codec
 }
 
 // BytesBody returns the byte slice of a response body.
@@ -19,6 +54,15 @@ func (r *Response) BytesBody() []byte {
 	return r.Body
 }
 
+// Close closes the response body of a streamed request. It is a no-op for
+// any other response, whose body is already read and closed.
+func (r *Response) Close() error {
+	if r.Stream == nil {
+		return nil
+	}
+	return r.Stream.Close()
+}
+
 // StringBody converts the byte slice of a response body to a string and
 // returns it.
 func (r *Response) StringBody() string {
@@ -33,3 +77,62 @@ func (r *Response) StringBody() string {
 func (r *Response) UnmarshalJson(target any) error {
 	return json.Unmarshal(r.BytesBody(), target)
 }
+
+// parseRetryAfter returns the duration of a Retry-After header value, which is
+// either a number of seconds or a HTTP date relative to now. A HTTP date in the
+// past results in a zero duration.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds > maxRetryAfterSeconds {
+			seconds = maxRetryAfterSeconds
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if d := date.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// getRetryAfter returns the duration of the Retry-After header if it is sent
+// with a 429 or 503 response, and whether it was.
+func getRetryAfter(statusCode int, headers http.Header) (time.Duration, bool) {
+	if statusCode != http.StatusTooManyRequests && statusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	return parseRetryAfter(headers.Get(HeaderRetryAfter), time.Now())
+}
+
+// UnmarshalXml uses the xml package from the standard library to return the
+// XML-decoded data which is stored in the value pointed to by target. The
+// UTF-8, US-ASCII and ISO-8859-1 encodings can be declared in the XML prolog.
+func (r *Response) UnmarshalXml(target any) error {
+	return decodeXml(r.BytesBody(), target)
+}
+
+// Decode decodes the response body into the value pointed to by target. The
+// codec is chosen by the Content-Type header of the response, from the codecs
+// registered as part of the client build or the built-in ones. JSON is assumed
+// if the header is absent. ErrNoCodec is returned if there is no codec for it.
+func (r *Response) Decode(target any) error {
+	contentType := r.ResponseHeaders.Get(HeaderContentType)
+	if contentType == "" {
+		contentType = ContentTypeJson
+	}
+	codec := findCodec(r.codecs, contentType)
+	if codec == nil {
+		return fmt.Errorf("%w: %s", ErrNoCodec, contentType)
+	}
+	return codec.Unmarshal(r.BytesBody(), target)
+}
@@ -1,7 +1,11 @@
 package goclient
 
 import (
+	"io"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -28,3 +32,153 @@ func TestUnmarshalJson(t *testing.T) {
 	r.UnmarshalJson(&jsonData)
 	assert.Equal(t, "foobar", jsonData.Want)
 }
+
+func TestUnmarshalXml(t *testing.T) {
+	var xmlData struct {
+		Want string `xml:"want"`
+	}
+	r := &Response{Body: []byte(`<?xml version="1.0" encoding="ISO-8859-1"?><response><want>foobar</want></response>`)}
+	err := r.UnmarshalXml(&xmlData)
+	assert.NoError(t, err)
+	assert.Equal(t, "foobar", xmlData.Want)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	tt := []struct {
+		name   string
+		value  string
+		expect time.Duration
+		ok     bool
+	}{
+		{
+			name:   "Empty",
+			value:  "",
+			expect: 0,
+			ok:     false,
+		},
+		{
+			name:   "Seconds",
+			value:  "120",
+			expect: 2 * time.Minute,
+			ok:     true,
+		},
+		{
+			name:   "NegativeSeconds",
+			value:  "-1",
+			expect: 0,
+			ok:     false,
+		},
+		{
+			name:   "OverflowSeconds",
+			value:  "9223372036854775807",
+			expect: time.Duration(maxRetryAfterSeconds) * time.Second,
+			ok:     true,
+		},
+		{
+			name:   "FutureDate",
+			value:  "Thu, 01 Jun 2023 12:00:30 GMT",
+			expect: 30 * time.Second,
+			ok:     true,
+		},
+		{
+			name:   "PastDate",
+			value:  "Thu, 01 Jun 2023 11:00:00 GMT",
+			expect: 0,
+			ok:     true,
+		},
+		{
+			name:   "Invalid",
+			value:  "foobar",
+			expect: 0,
+			ok:     false,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			have, ok := parseRetryAfter(tc.value, now)
+			assert.Equal(t, tc.expect, have)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestGetRetryAfter(t *testing.T) {
+	tt := []struct {
+		name       string
+		statusCode int
+		headers    http.Header
+		expect     time.Duration
+		ok         bool
+	}{
+		{
+			name:       "TooManyRequests",
+			statusCode: http.StatusTooManyRequests,
+			headers:    http.Header{HeaderRetryAfter: {"5"}},
+			expect:     5 * time.Second,
+			ok:         true,
+		},
+		{
+			name:       "ServiceUnavailable",
+			statusCode: http.StatusServiceUnavailable,
+			headers:    http.Header{HeaderRetryAfter: {"5"}},
+			expect:     5 * time.Second,
+			ok:         true,
+		},
+		{
+			name:       "Zero",
+			statusCode: http.StatusServiceUnavailable,
+			headers:    http.Header{HeaderRetryAfter: {"0"}},
+			expect:     0,
+			ok:         true,
+		},
+		{
+			name:       "PastDate",
+			statusCode: http.StatusServiceUnavailable,
+			headers:    http.Header{HeaderRetryAfter: {"Wed, 21 Oct 2015 07:28:00 GMT"}},
+			expect:     0,
+			ok:         true,
+		},
+		{
+			name:       "OtherStatus",
+			statusCode: http.StatusBadGateway,
+			headers:    http.Header{HeaderRetryAfter: {"5"}},
+			expect:     0,
+			ok:         false,
+		},
+		{
+			name:       "Absent",
+			statusCode: http.StatusTooManyRequests,
+			headers:    http.Header{},
+			expect:     0,
+			ok:         false,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			have, ok := getRetryAfter(tc.statusCode, tc.headers)
+			assert.Equal(t, tc.expect, have)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestClose(t *testing.T) {
+	r := &Response{Body: []byte("foobar")}
+	assert.NoError(t, r.Close())
+
+	stream := &mockReadCloser{Reader: strings.NewReader("foobar")}
+	r = &Response{Stream: stream}
+	assert.NoError(t, r.Close())
+	assert.True(t, stream.closed)
+}
+
+type mockReadCloser struct {
+	io.Reader
+	closed bool
+}
+
+func (m *mockReadCloser) Close() error {
+	m.closed = true
+	return nil
+}
@@ -0,0 +1,202 @@
+package goclient
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = 10 * time.Second
+	defaultRetryMaxRetryAfter  = time.Minute
+)
+
+// defaultRetryStatusCodes are the response status codes that are retried if a
+// retry policy does not define its own.
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy defines when and how failed client requests are retried.
+// Requests are retried if the connection fails or the web service responds
+// with one of the retryable status codes. The wait between attempts grows
+// exponentially and is randomised with full jitter.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of attempts, including the first one. A
+	// value less than 2 disables retries.
+	MaxAttempts int
+
+	// InitialBackoff is the upper bound of the wait before the first retry.
+	// It is doubled for every subsequent retry. Defaults to 100ms.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the upper bound of the wait between attempts. Defaults
+	// to 10s.
+	MaxBackoff time.Duration
+
+	// MaxElapsedTime is the max duration spent on a request, including all of
+	// its attempts and waits. No retry is attempted once it would be
+	// exceeded. A zero value means no limit.
+	MaxElapsedTime time.Duration
+
+	// MaxRetryAfter caps the wait requested by the Retry-After header of a
+	// 429 or 503 response, which is honoured instead of the backoff. Defaults
+	// to 1m.
+	MaxRetryAfter time.Duration
+
+	// StatusCodes are the response status codes that are retried. Defaults
+	// to 429, 502, 503 and 504.
+	StatusCodes []int
+
+	// RetryNonIdempotent allows requests that are not idempotent, such as
+	// POST and PATCH, to be retried. Only GET, HEAD, OPTIONS, TRACE, PUT and
+	// DELETE requests are retried by default.
+	RetryNonIdempotent bool
+}
+
+// backoff returns a random wait between zero and the exponential backoff of
+// the given retry, where zero is the first retry.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = defaultRetryInitialBackoff
+	}
+	limit := p.MaxBackoff
+	if limit <= 0 {
+		limit = defaultRetryMaxBackoff
+	}
+
+	ceiling := initial
+	for i := 0; i < retry && ceiling < limit; i++ {
+		ceiling *= 2
+	}
+	if ceiling > limit {
+		ceiling = limit
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// wait returns the duration to wait before the given retry. The Retry-After
+// header of the response takes precedence over the backoff, up to a cap, even
+// if it asks for no wait.
+func (p *RetryPolicy) wait(retry int, response *Response) time.Duration {
+	if response == nil || (response.RetryAfter <= 0 && !response.hasRetryAfter) {
+		return p.backoff(retry)
+	}
+	limit := p.MaxRetryAfter
+	if limit <= 0 {
+		limit = defaultRetryMaxRetryAfter
+	}
+	if response.RetryAfter > limit {
+		return limit
+	}
+	return response.RetryAfter
+}
+
+// retryStatus reports whether the response status code is retryable.
+func (p *RetryPolicy) retryStatus(statusCode int) bool {
+	statusCodes := p.StatusCodes
+	if statusCodes == nil {
+		statusCodes = defaultRetryStatusCodes
+	}
+	for _, code := range statusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// retryMethod reports whether requests of the given method may be retried.
+func (p *RetryPolicy) retryMethod(method string) bool {
+	if p.RetryNonIdempotent {
+		return true
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// doWithRetry performs a client request and retries it according to the
+// retry policy of the client build. The response or error of the last attempt
+// is returned once the request succeeds or the policy is exhausted.
+func (c *client) doWithRetry(request *http.Request, opts *requestOptions) (*Response, error) {
+	policy := c.builder.retryPolicy
+	if policy == nil || policy.MaxAttempts < 2 || !policy.retryMethod(request.Method) {
+		return c.doAttempt(request, opts)
+	}
+
+	ctx := request.Context()
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		// The request body is consumed by each attempt, so a fresh copy is
+		// required for every retry.
+		attemptRequest := request
+		if attempt > 1 {
+			attemptRequest = request.Clone(ctx)
+			if request.GetBody != nil {
+				body, err := request.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptRequest.Body = body
+			}
+		}
+
+		response, err := c.doAttempt(attemptRequest, opts)
+		if attempt >= policy.MaxAttempts || !c.shouldRetry(request, response, err) {
+			return response, err
+		}
+
+		wait := policy.wait(attempt-1, response)
+		if policy.MaxElapsedTime > 0 && time.Since(start)+wait > policy.MaxElapsedTime {
+			return response, err
+		}
+		if response != nil {
+			response.Close()
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, wrapContextError(ctx, err)
+		}
+	}
+}
+
+// shouldRetry reports whether the outcome of an attempt is retryable. Errors
+// caused by the request context, an open circuit, the rate limit, an
+// oversized response body or a certificate pin mismatch are never retried.
+func (c *client) shouldRetry(request *http.Request, response *Response, err error) bool {
+	if request.GetBody == nil && request.Body != nil && request.Body != http.NoBody {
+		return false
+	}
+	if err != nil {
+		var circuitErr *ErrCircuitOpen
+		var sizeErr *ErrResponseTooLarge
+		var pinErr *ErrPinMismatch
+		return request.Context().Err() == nil && !errors.As(err, &circuitErr) &&
+			!errors.Is(err, ErrRateLimited) && !errors.As(err, &sizeErr) && !errors.As(err, &pinErr)
+	}
+	return c.builder.retryPolicy.retryStatus(response.StatusCode)
+}
+
+// sleepContext pauses the current goroutine for the given duration or until
+// ctx is done, whichever happens first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,306 @@
+package goclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockTransport allows a function to be used as the transport of a HTTP client.
+type mockTransport func(r *http.Request) (*http.Response, error)
+
+func (m mockTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return m(r)
+}
+
+func TestBackoff(t *testing.T) {
+	tt := []struct {
+		name   string
+		policy RetryPolicy
+		retry  int
+		expect time.Duration
+	}{
+		{
+			name:   "DefaultInitial",
+			policy: RetryPolicy{},
+			retry:  0,
+			expect: 100 * time.Millisecond,
+		},
+		{
+			name:   "Exponential",
+			policy: RetryPolicy{InitialBackoff: time.Second, MaxBackoff: time.Minute},
+			retry:  3,
+			expect: 8 * time.Second,
+		},
+		{
+			name:   "Capped",
+			policy: RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second},
+			retry:  10,
+			expect: 5 * time.Second,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				have := tc.policy.backoff(tc.retry)
+				assert.GreaterOrEqual(t, have, time.Duration(0))
+				assert.LessOrEqual(t, have, tc.expect)
+			}
+		})
+	}
+}
+
+func TestWait(t *testing.T) {
+	p := &RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	assert.LessOrEqual(t, p.wait(0, nil), time.Millisecond)
+	assert.LessOrEqual(t, p.wait(0, &Response{}), time.Millisecond)
+	assert.Equal(t, 30*time.Second, p.wait(0, &Response{RetryAfter: 30 * time.Second}))
+	assert.Zero(t, p.wait(0, &Response{RetryAfter: 0, hasRetryAfter: true}))
+	assert.Equal(t, time.Minute, p.wait(0, &Response{RetryAfter: time.Hour}))
+
+	p.MaxRetryAfter = 10 * time.Second
+	assert.Equal(t, 10*time.Second, p.wait(0, &Response{RetryAfter: 30 * time.Second}))
+}
+
+func TestRetryStatus(t *testing.T) {
+	p := &RetryPolicy{}
+	assert.True(t, p.retryStatus(http.StatusServiceUnavailable))
+	assert.False(t, p.retryStatus(http.StatusInternalServerError))
+
+	p = &RetryPolicy{StatusCodes: []int{http.StatusInternalServerError}}
+	assert.True(t, p.retryStatus(http.StatusInternalServerError))
+	assert.False(t, p.retryStatus(http.StatusServiceUnavailable))
+}
+
+func TestRetryMethod(t *testing.T) {
+	p := &RetryPolicy{}
+	assert.True(t, p.retryMethod(http.MethodGet))
+	assert.True(t, p.retryMethod(http.MethodPut))
+	assert.False(t, p.retryMethod(http.MethodPost))
+	assert.False(t, p.retryMethod(http.MethodPatch))
+
+	p = &RetryPolicy{RetryNonIdempotent: true}
+	assert.True(t, p.retryMethod(http.MethodPost))
+}
+
+func TestDoWithRetry(t *testing.T) {
+	tt := []struct {
+		name       string
+		policy     *RetryPolicy
+		method     string
+		failures   int32
+		failStatus int
+		expect     int
+		attempts   int32
+	}{
+		{
+			name:       "NoPolicy",
+			policy:     nil,
+			method:     http.MethodGet,
+			failures:   1,
+			failStatus: http.StatusServiceUnavailable,
+			expect:     http.StatusServiceUnavailable,
+			attempts:   1,
+		},
+		{
+			name:       "RetryStatus",
+			policy:     &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			method:     http.MethodGet,
+			failures:   2,
+			failStatus: http.StatusBadGateway,
+			expect:     http.StatusOK,
+			attempts:   3,
+		},
+		{
+			name:       "AttemptsExhausted",
+			policy:     &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+			method:     http.MethodGet,
+			failures:   5,
+			failStatus: http.StatusGatewayTimeout,
+			expect:     http.StatusGatewayTimeout,
+			attempts:   2,
+		},
+		{
+			name:       "StatusNotRetryable",
+			policy:     &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			method:     http.MethodGet,
+			failures:   1,
+			failStatus: http.StatusInternalServerError,
+			expect:     http.StatusInternalServerError,
+			attempts:   1,
+		},
+		{
+			name:       "NonIdempotent",
+			policy:     &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			method:     http.MethodPost,
+			failures:   1,
+			failStatus: http.StatusServiceUnavailable,
+			expect:     http.StatusServiceUnavailable,
+			attempts:   1,
+		},
+		{
+			name: "RetryNonIdempotent",
+			policy: &RetryPolicy{
+				MaxAttempts:        3,
+				InitialBackoff:     time.Millisecond,
+				RetryNonIdempotent: true,
+			},
+			method:     http.MethodPost,
+			failures:   1,
+			failStatus: http.StatusServiceUnavailable,
+			expect:     http.StatusOK,
+			attempts:   2,
+		},
+		{
+			name: "MaxElapsedTime",
+			policy: &RetryPolicy{
+				MaxAttempts:    3,
+				InitialBackoff: time.Hour,
+				MaxBackoff:     time.Hour,
+				MaxElapsedTime: time.Nanosecond,
+			},
+			method:     http.MethodGet,
+			failures:   1,
+			failStatus: http.StatusServiceUnavailable,
+			expect:     http.StatusServiceUnavailable,
+			attempts:   1,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts int32
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestBody, err := io.ReadAll(r.Body)
+				require.NoError(t, err, "expected no errors")
+				assert.Equal(t, `{"A":"foo","B":"bar"}`, string(requestBody))
+
+				if atomic.AddInt32(&attempts, 1) <= tc.failures {
+					w.WriteHeader(tc.failStatus)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer s.Close()
+
+			c := &client{builder: &builder{baseURL: s.URL, retryPolicy: tc.policy}}
+			body := &mockCore{A: "foo", B: "bar"}
+			response, err := c.doRequest(context.Background(), tc.method, "/api", body)
+			require.NoError(t, err, "expected no errors")
+			assert.Equal(t, tc.expect, response.StatusCode)
+			assert.Equal(t, tc.attempts, atomic.LoadInt32(&attempts))
+		})
+	}
+
+	t.Run("ConnectionError", func(t *testing.T) {
+		var attempts int32
+		c := &client{builder: &builder{
+			retryPolicy: &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+		}}
+		c.initOnce.Do(func() {
+			c.client = &http.Client{Transport: mockTransport(func(r *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&attempts, 1)
+				return nil, errors.New("connection refused")
+			})}
+		})
+
+		response, err := c.doRequest(context.Background(), http.MethodGet, "http://foobar.com", nil)
+		assert.Error(t, err)
+		assert.Empty(t, response, "response should be nil")
+		assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("RetryAfter", func(t *testing.T) {
+		var attempts int32
+		var first time.Time
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				first = time.Now()
+				w.Header().Set(HeaderRetryAfter, "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			assert.GreaterOrEqual(t, time.Since(first), time.Second)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer s.Close()
+
+		c := &client{builder: &builder{
+			baseURL: s.URL,
+			retryPolicy: &RetryPolicy{
+				MaxAttempts:    2,
+				InitialBackoff: time.Millisecond,
+			},
+		}}
+		response, err := c.doRequest(context.Background(), http.MethodGet, "/api", nil)
+		require.NoError(t, err, "expected no errors")
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("ZeroRetryAfter", func(t *testing.T) {
+		var attempts int32
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				w.Header().Set(HeaderRetryAfter, "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer s.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		c := &client{builder: &builder{
+			baseURL: s.URL,
+			retryPolicy: &RetryPolicy{
+				MaxAttempts:    2,
+				InitialBackoff: time.Hour,
+				MaxBackoff:     time.Hour,
+			},
+		}}
+		response, err := c.doRequest(ctx, http.MethodGet, "/api", nil)
+		require.NoError(t, err, "expected no errors")
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("CancelledBackoff", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer s.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		c := &client{builder: &builder{
+			baseURL: s.URL,
+			retryPolicy: &RetryPolicy{
+				MaxAttempts:    3,
+				InitialBackoff: time.Hour,
+				MaxBackoff:     time.Hour,
+			},
+		}}
+		response, err := c.doRequest(ctx, http.MethodGet, "/api", nil)
+		assert.ErrorIs(t, err, ErrRequestCancelled)
+		assert.Empty(t, response, "response should be nil")
+	})
+}
+
+func TestSleepContext(t *testing.T) {
+	assert.NoError(t, sleepContext(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, sleepContext(ctx, time.Hour), context.Canceled)
+}
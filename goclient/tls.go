@@ -0,0 +1,167 @@
+package goclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// TLSConfig defines the TLS settings of connections to web services, such as
+// internal services signed by a private CA or requiring mutual TLS.
+type TLSConfig struct {
+	// RootCAFiles are PEM files of the CA certificates used to verify web
+	// services. They are added to RootCAs if it is set.
+	RootCAFiles []string
+
+	// RootCAs is the pool of CA certificates used to verify web services.
+	// The system pool is used if neither RootCAs nor RootCAFiles is set.
+	RootCAs *x509.CertPool
+
+	// CertFile and KeyFile are the PEM files of the client certificate and
+	// its private key sent to web services that require mutual TLS.
+	CertFile string
+	KeyFile  string
+
+	// Certificates are client certificates sent to web services that require
+	// mutual TLS, in addition to the one loaded from CertFile and KeyFile.
+	Certificates []tls.Certificate
+
+	// MinVersion is the min TLS version, such as tls.VersionTLS13. Defaults
+	// to the default of the standard library, which is TLS 1.2.
+	MinVersion uint16
+
+	// CipherSuites are the enabled cipher suites of TLS 1.2 and earlier. The
+	// cipher suites of TLS 1.3 are not configurable. Defaults to the cipher
+	// suites of the standard library.
+	CipherSuites []uint16
+
+	// ServerName overrides the host name used to verify the certificate of a
+	// web service, such as when it is reached through an IP address.
+	ServerName string
+
+	// ReloadInterval is the interval at which CertFile, KeyFile and
+	// RootCAFiles are polled for changes. Changed files are reloaded on the
+	// next TLS handshake, so rotated certificates are used without building
+	// a new client. If a file fails to load, such as while it is rewritten,
+	// the previous certificates are kept until the next poll. A zero value
+	// loads the files once.
+	ReloadInterval time.Duration
+}
+
+// newTLSConfig returns the TLS config of the standard library for config, with
+// its CA and client certificate files loaded. If pins is not nil, they are
+// checked once the certificate chain of a web service is verified.
+func newTLSConfig(config *TLSConfig, pins *CertificatePins) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:   config.MinVersion,
+		CipherSuites: config.CipherSuites,
+		ServerName:   config.ServerName,
+		Certificates: config.Certificates,
+	}
+	if config.ReloadInterval > 0 {
+		reloader, err := newTLSReloader(config)
+		if err != nil {
+			return nil, err
+		}
+		verify := reloader.apply(tlsConfig)
+
+		// Without root CA files to reload, the pool of the config is used
+		// as it is by the verification of the standard library.
+		if len(config.RootCAFiles) == 0 {
+			tlsConfig.RootCAs = config.RootCAs
+		}
+		if pins != nil {
+			tlsConfig.VerifyConnection = pins.verifyConnection(verify)
+		}
+		return tlsConfig, nil
+	}
+	if pins != nil {
+		tlsConfig.VerifyConnection = pins.verifyConnection(nil)
+	}
+
+	rootCAs, err := loadRootCAs(config.RootCAs, config.RootCAFiles)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.RootCAs = rootCAs
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("goclient: load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = append([]tls.Certificate{cert}, config.Certificates...)
+	}
+	return tlsConfig, nil
+}
+
+// loadRootCAs returns a copy of pool with the CA certificates of the PEM files
+// added to it. The pool is returned unchanged if there are no files.
+func loadRootCAs(pool *x509.CertPool, files []string) (*x509.CertPool, error) {
+	if len(files) == 0 {
+		return pool, nil
+	}
+	if pool == nil {
+		pool = x509.NewCertPool()
+	} else {
+		pool = pool.Clone()
+	}
+	for _, file := range files {
+		pem, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("goclient: load root CA: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("goclient: load root CA: no certificates found in %s", file)
+		}
+	}
+	return pool, nil
+}
+
+// newTLSDialer returns a function that dials TLS connections with config. The
+// standard library leaves the server name of the connection state empty for IP
+// addresses, so it is set to the dialled host before the state is passed to
+// VerifyConnection.
+func newTLSDialer(config *tls.Config, dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		cfg := config.Clone()
+		if cfg.ServerName == "" {
+			cfg.ServerName = host
+		}
+		if verify := cfg.VerifyConnection; verify != nil {
+			serverName := cfg.ServerName
+			cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+				if cs.ServerName == "" {
+					cs.ServerName = serverName
+				}
+				return verify(cs)
+			}
+		}
+		return (&tls.Dialer{NetDialer: dialer, Config: cfg}).DialContext(ctx, network, addr)
+	}
+}
+
+// getTLSConfig returns the TLS config of the client or nil if neither it nor
+// certificate pins are defined as part of the client build. It is loaded once,
+// when the client is built, and an error loading its files is returned by every
+// request.
+func (c *client) getTLSConfig() (*tls.Config, error) {
+	c.tlsOnce.Do(func() {
+		config := c.builder.tlsConfig
+		if config == nil && c.builder.pins != nil {
+			config = &TLSConfig{}
+		}
+		if config != nil {
+			c.tlsConfig, c.tlsErr = newTLSConfig(config, c.builder.pins)
+		}
+	})
+	return c.tlsConfig, c.tlsErr
+}
@@ -0,0 +1,236 @@
+package goclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePEM writes the PEM blocks of the given type to a file in dir and
+// returns its path.
+func writePEM(t *testing.T, dir, name, blockType string, blocks ...[]byte) string {
+	t.Helper()
+	var data []byte
+	for _, b := range blocks {
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: b})...)
+	}
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+// mockCert returns a self-signed certificate for the DNS name name and the
+// given IP addresses, usable by both clients and servers, and writes it and its
+// private key to PEM files in dir.
+func mockCert(t *testing.T, dir, name string, ips ...net.IP) (*x509.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		DNSNames:              []string{name},
+		IPAddresses:           ips,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return cert, writePEM(t, dir, name+".crt", "CERTIFICATE", der), writePEM(t, dir, name+".key", "EC PRIVATE KEY", keyDER)
+}
+
+// newMockTLSServer returns a started TLS server that responds with the common
+// name of the client certificate, if any.
+func newMockTLSServer(t *testing.T, config *tls.Config) *httptest.Server {
+	t.Helper()
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+		}
+	}))
+	s.TLS = config
+	s.StartTLS()
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	_, certFile, keyFile := mockCert(t, dir, "client")
+	emptyFile := filepath.Join(dir, "empty.pem")
+	require.NoError(t, os.WriteFile(emptyFile, []byte("not a certificate"), 0o600))
+
+	tt := []struct {
+		name     string
+		config   *TLSConfig
+		certs    int
+		rootCAs  bool
+		hasError bool
+	}{
+		{
+			name:   "Empty",
+			config: &TLSConfig{},
+		},
+		{
+			name:    "RootCAs",
+			config:  &TLSConfig{RootCAs: x509.NewCertPool()},
+			rootCAs: true,
+		},
+		{
+			name:    "RootCAFiles",
+			config:  &TLSConfig{RootCAFiles: []string{certFile}},
+			rootCAs: true,
+		},
+		{
+			name:   "ClientCertificate",
+			config: &TLSConfig{CertFile: certFile, KeyFile: keyFile, Certificates: []tls.Certificate{{}}},
+			certs:  2,
+		},
+		{
+			name:     "MissingRootCAFile",
+			config:   &TLSConfig{RootCAFiles: []string{filepath.Join(dir, "missing.pem")}},
+			hasError: true,
+		},
+		{
+			name:     "InvalidRootCAFile",
+			config:   &TLSConfig{RootCAFiles: []string{certFile, emptyFile}},
+			hasError: true,
+		},
+		{
+			name:     "MissingKeyFile",
+			config:   &TLSConfig{CertFile: certFile},
+			hasError: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := newTLSConfig(tc.config, nil)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Nil(t, have)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, have.Certificates, tc.certs)
+			assert.Equal(t, tc.rootCAs, have.RootCAs != nil)
+		})
+	}
+}
+
+func TestDoRequestTLS(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, certFile, keyFile := mockCert(t, dir, "client")
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := newMockTLSServer(t, &tls.Config{})
+	serverCAFile := writePEM(t, dir, "server.pem", "CERTIFICATE", server.Certificate().Raw)
+	mutualServer := newMockTLSServer(t, &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs})
+	tls12Server := newMockTLSServer(t, &tls.Config{MaxVersion: tls.VersionTLS12})
+
+	tt := []struct {
+		name     string
+		server   *httptest.Server
+		config   *TLSConfig
+		expect   string
+		hasError bool
+	}{
+		{
+			name:     "UnknownAuthority",
+			server:   server,
+			hasError: true,
+		},
+		{
+			name:   "RootCAFiles",
+			server: server,
+			config: &TLSConfig{RootCAFiles: []string{serverCAFile}},
+		},
+		{
+			name:   "RootCAs",
+			server: server,
+			config: &TLSConfig{RootCAs: server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs},
+		},
+		{
+			name:   "ServerName",
+			server: server,
+			config: &TLSConfig{RootCAFiles: []string{serverCAFile}, ServerName: "example.com"},
+		},
+		{
+			name:     "WrongServerName",
+			server:   server,
+			config:   &TLSConfig{RootCAFiles: []string{serverCAFile}, ServerName: "foobar.com"},
+			hasError: true,
+		},
+		{
+			name:   "ClientCertificate",
+			server: mutualServer,
+			config: &TLSConfig{RootCAFiles: []string{serverCAFile}, CertFile: certFile, KeyFile: keyFile},
+			expect: "client",
+		},
+		{
+			name:     "MissingClientCertificate",
+			server:   mutualServer,
+			config:   &TLSConfig{RootCAFiles: []string{serverCAFile}},
+			hasError: true,
+		},
+		{
+			name:     "MinVersion",
+			server:   tls12Server,
+			config:   &TLSConfig{RootCAFiles: []string{serverCAFile}, MinVersion: tls.VersionTLS13},
+			hasError: true,
+		},
+		{
+			name:   "CipherSuites",
+			server: tls12Server,
+			config: &TLSConfig{
+				RootCAFiles:  []string{serverCAFile},
+				CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+			},
+		},
+		{
+			name:     "MissingFile",
+			server:   server,
+			config:   &TLSConfig{RootCAFiles: []string{filepath.Join(dir, "missing.pem")}},
+			hasError: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewBuild().SetBaseURL(tc.server.URL)
+			if tc.config != nil {
+				b.SetTLSConfig(*tc.config)
+			}
+			response, err := b.Build().Do(context.Background(), http.MethodGet, "/", nil)
+			if tc.hasError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, string(response.Body))
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package goclient
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// tlsReloader keeps the client certificate and root CAs of a TLS config up to
+// date with their files. The files are polled on the TLS handshakes of the
+// client, at most once per interval, rather than by a goroutine, so nothing is
+// left running once the client is no longer used. It is concurrent safe.
+type tlsReloader struct {
+	config *TLSConfig
+	now    func() time.Time
+
+	mu      sync.Mutex
+	checked time.Time
+	files   map[string][]byte
+	cert    *tls.Certificate
+	rootCAs *x509.CertPool
+}
+
+// newTLSReloader returns a reloader with the files of config loaded. An error
+// is returned if they fail to load.
+func newTLSReloader(config *TLSConfig) (*tlsReloader, error) {
+	r := &tlsReloader{config: config, now: time.Now}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	r.checked = r.now()
+	return r, nil
+}
+
+// apply sets the callbacks of tlsConfig that return the reloaded certificates.
+// The root CAs are checked by a custom verifier, since the standard library
+// only reads them from the config when it is cloned by the transport. The
+// verifier is returned, or nil if the root CAs are not reloaded.
+func (r *tlsReloader) apply(tlsConfig *tls.Config) chainVerifier {
+	if r.config.CertFile != "" || r.config.KeyFile != "" {
+		tlsConfig.GetClientCertificate = r.getClientCertificate
+	}
+	if len(r.config.RootCAFiles) == 0 {
+		return nil
+	}
+	tlsConfig.InsecureSkipVerify = true
+	tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+		_, err := r.verifyChains(cs)
+		return err
+	}
+	return r.verifyChains
+}
+
+// load reads the files of the TLS config and parses them if any has changed
+// since they were last loaded.
+func (r *tlsReloader) load() error {
+	paths := append([]string{}, r.config.RootCAFiles...)
+	if r.config.CertFile != "" || r.config.KeyFile != "" {
+		paths = append(paths, r.config.CertFile, r.config.KeyFile)
+	}
+	files := make(map[string][]byte, len(paths))
+	changed := r.files == nil
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("goclient: reload TLS files: %w", err)
+		}
+		files[path] = data
+		if !bytes.Equal(data, r.files[path]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	rootCAs, err := loadRootCAs(r.config.RootCAs, r.config.RootCAFiles)
+	if err != nil {
+		return err
+	}
+	var cert *tls.Certificate
+	if r.config.CertFile != "" || r.config.KeyFile != "" {
+		c, err := tls.X509KeyPair(files[r.config.CertFile], files[r.config.KeyFile])
+		if err != nil {
+			return fmt.Errorf("goclient: load client certificate: %w", err)
+		}
+		cert = &c
+	}
+	r.files, r.cert, r.rootCAs = files, cert, rootCAs
+	return nil
+}
+
+// get returns the current client certificate and root CAs, which are first
+// reloaded if the interval has elapsed since the files were last polled.
+func (r *tlsReloader) get() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now := r.now(); now.Sub(r.checked) >= r.config.ReloadInterval {
+		r.checked = now
+		// An error keeps the previous certificates, since a file may be
+		// caught part way through being rewritten.
+		_ = r.load()
+	}
+	return r.cert, r.rootCAs
+}
+
+// getClientCertificate returns the client certificate requested by a web
+// service. The reloaded certificate is preferred over the static ones.
+func (r *tlsReloader) getClientCertificate(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cert, _ := r.get()
+	if cert == nil || info.SupportsCertificate(cert) == nil {
+		return cert, nil
+	}
+	for i := range r.config.Certificates {
+		if info.SupportsCertificate(&r.config.Certificates[i]) == nil {
+			return &r.config.Certificates[i], nil
+		}
+	}
+	return cert, nil
+}
+
+// verifyChains verifies the certificate chain and host name of a web service
+// against the reloaded root CAs, in place of the verification of the standard
+// library, and returns the verified chains.
+func (r *tlsReloader) verifyChains(cs tls.ConnectionState) ([][]*x509.Certificate, error) {
+	if len(cs.PeerCertificates) == 0 {
+		return nil, errors.New("goclient: web service presented no certificates")
+	}
+	if cs.ServerName == "" {
+		return nil, errors.New("goclient: cannot verify web service without a server name")
+	}
+	_, rootCAs := r.get()
+	opts := x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         rootCAs,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	return cs.PeerCertificates[0].Verify(opts)
+}
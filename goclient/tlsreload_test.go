@@ -0,0 +1,192 @@
+package goclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// copyFile copies the file at src to dst, replacing dst.
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+	data, err := os.ReadFile(src)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dst, data, 0o600))
+}
+
+func TestTLSReloaderGet(t *testing.T) {
+	dir := t.TempDir()
+	_, certA, keyA := mockCert(t, dir, "a")
+	_, certB, keyB := mockCert(t, dir, "b")
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	copyFile(t, certA, certFile)
+	copyFile(t, keyA, keyFile)
+
+	clock := &mockClock{now: time.Now()}
+	config := &TLSConfig{CertFile: certFile, KeyFile: keyFile, RootCAFiles: []string{certFile}, ReloadInterval: time.Minute}
+	r, err := newTLSReloader(config)
+	require.NoError(t, err)
+	r.now, r.checked = clock.Now, clock.now
+	commonName := func() string {
+		cert, rootCAs := r.get()
+		require.NotNil(t, rootCAs)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		return leaf.Subject.CommonName
+	}
+	assert.Equal(t, "a", commonName())
+
+	// The files are not polled again until the interval has elapsed.
+	copyFile(t, certB, certFile)
+	copyFile(t, keyB, keyFile)
+	clock.now = clock.now.Add(30 * time.Second)
+	assert.Equal(t, "a", commonName())
+	clock.now = clock.now.Add(30 * time.Second)
+	assert.Equal(t, "b", commonName())
+
+	// A file that fails to load keeps the previous certificate.
+	require.NoError(t, os.WriteFile(keyFile, []byte("partial"), 0o600))
+	clock.now = clock.now.Add(time.Minute)
+	assert.Equal(t, "b", commonName())
+	copyFile(t, keyA, keyFile)
+	copyFile(t, certA, certFile)
+	clock.now = clock.now.Add(time.Minute)
+	assert.Equal(t, "a", commonName())
+}
+
+func TestNewTLSReloader(t *testing.T) {
+	dir := t.TempDir()
+	_, certFile, keyFile := mockCert(t, dir, "client")
+
+	t.Run("MissingFile", func(t *testing.T) {
+		_, err := newTLSReloader(&TLSConfig{CertFile: certFile, KeyFile: filepath.Join(dir, "missing.key"), ReloadInterval: time.Minute})
+		assert.Error(t, err)
+	})
+	t.Run("Callbacks", func(t *testing.T) {
+		have, err := newTLSConfig(&TLSConfig{CertFile: certFile, KeyFile: keyFile, RootCAFiles: []string{certFile}, ReloadInterval: time.Minute}, nil)
+		require.NoError(t, err)
+		assert.NotNil(t, have.GetClientCertificate)
+		assert.NotNil(t, have.VerifyConnection)
+		assert.True(t, have.InsecureSkipVerify)
+		assert.Empty(t, have.Certificates)
+		assert.Nil(t, have.RootCAs)
+	})
+	t.Run("CertificateOnly", func(t *testing.T) {
+		have, err := newTLSConfig(&TLSConfig{CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Minute}, nil)
+		require.NoError(t, err)
+		assert.NotNil(t, have.GetClientCertificate)
+		assert.Nil(t, have.VerifyConnection)
+		assert.False(t, have.InsecureSkipVerify)
+	})
+	t.Run("RootCAsPool", func(t *testing.T) {
+		pool := x509.NewCertPool()
+		have, err := newTLSConfig(&TLSConfig{RootCAs: pool, CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Minute}, nil)
+		require.NoError(t, err)
+		assert.Same(t, pool, have.RootCAs)
+	})
+}
+
+func TestDoRequestTLSReloadRootCAs(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, certFile, keyFile := mockCert(t, dir, "client")
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	s := newMockTLSServer(t, &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs})
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(s.Certificate())
+
+	c := NewBuild().
+		SetBaseURL(s.URL).
+		SetTLSConfig(TLSConfig{RootCAs: rootCAs, CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Minute}).
+		Build()
+	response, err := c.Do(context.Background(), http.MethodGet, "/", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "client", string(response.Body))
+}
+
+func TestDoRequestTLSReload(t *testing.T) {
+	dir := t.TempDir()
+	localhost := net.IPv4(127, 0, 0, 1)
+	_, serverCertA, serverKeyA := mockCert(t, dir, "server-a", localhost)
+	_, serverCertB, serverKeyB := mockCert(t, dir, "server-b", localhost)
+	_, serverCertC, serverKeyC := mockCert(t, dir, "server-c")
+	clientA, clientCertA, clientKeyA := mockCert(t, dir, "client-a")
+	clientB, clientCertB, clientKeyB := mockCert(t, dir, "client-b")
+
+	pairA, err := tls.LoadX509KeyPair(serverCertA, serverKeyA)
+	require.NoError(t, err)
+	pairB, err := tls.LoadX509KeyPair(serverCertB, serverKeyB)
+	require.NoError(t, err)
+	pairC, err := tls.LoadX509KeyPair(serverCertC, serverKeyC)
+	require.NoError(t, err)
+	var serverPair atomic.Pointer[tls.Certificate]
+	serverPair.Store(&pairA)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientA)
+	clientCAs.AddCert(clientB)
+	s := newMockTLSServer(t, &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return &tls.Config{
+				Certificates: []tls.Certificate{*serverPair.Load()},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    clientCAs,
+			}, nil
+		},
+	})
+	s.Config.SetKeepAlivesEnabled(false)
+
+	caFile, certFile, keyFile := filepath.Join(dir, "ca.pem"), filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	copyFile(t, serverCertA, caFile)
+	copyFile(t, clientCertA, certFile)
+	copyFile(t, clientKeyA, keyFile)
+
+	c := NewBuild().
+		SetBaseURL(s.URL).
+		SetTLSConfig(TLSConfig{RootCAFiles: []string{caFile}, CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Nanosecond}).
+		Build()
+	do := func() (string, error) {
+		response, err := c.Do(context.Background(), http.MethodGet, "/", nil)
+		if err != nil {
+			return "", err
+		}
+		return string(response.Body), nil
+	}
+
+	have, err := do()
+	require.NoError(t, err)
+	assert.Equal(t, "client-a", have)
+
+	// The rotated client certificate is sent on the next connection.
+	copyFile(t, clientCertB, certFile)
+	copyFile(t, clientKeyB, keyFile)
+	have, err = do()
+	require.NoError(t, err)
+	assert.Equal(t, "client-b", have)
+
+	// A server certificate signed by an unknown CA is rejected until the CA
+	// bundle is rotated.
+	serverPair.Store(&pairB)
+	_, err = do()
+	assert.Error(t, err)
+	copyFile(t, serverCertB, caFile)
+	have, err = do()
+	require.NoError(t, err)
+	assert.Equal(t, "client-b", have)
+
+	// A trusted server certificate for another host is rejected.
+	serverPair.Store(&pairC)
+	copyFile(t, serverCertC, caFile)
+	_, err = do()
+	assert.ErrorContains(t, err, "cannot validate certificate for 127.0.0.1")
+}
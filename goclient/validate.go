@@ -0,0 +1,214 @@
+package goclient
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// BuildE provides a custom HTTP client implementation with the desired config,
+// like Build, but returns an error rather than a client if the config is not
+// valid. It allows misconfiguration to fail at service startup.
+func (b *builder) BuildE() (Client, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return b.Build(), nil
+}
+
+// Validate checks the config of the client build. All of its errors are
+// returned joined together, or nil if the config is valid.
+func (b *builder) Validate() error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("goclient: "+format, args...))
+	}
+
+	if b.baseURL != "" {
+		u, err := url.Parse(b.baseURL)
+		switch {
+		case err != nil:
+			add("invalid base URL: %w", err)
+		case u.Scheme != "http" && u.Scheme != "https":
+			add("base URL %q must have an http or https scheme", b.baseURL)
+		case u.Host == "":
+			add("base URL %q must have a host", b.baseURL)
+		case u.RawQuery != "" || u.Fragment != "":
+			add("base URL %q must not have a query or fragment", b.baseURL)
+		}
+	}
+
+	if b.connectionTimeout < 0 {
+		add("connection timeout must not be negative, got %s", b.connectionTimeout)
+	}
+	if b.responseTimeout < 0 {
+		add("response timeout must not be negative, got %s", b.responseTimeout)
+	}
+	if b.maxIdleConnsPerHost < 0 {
+		add("max idle connections per host must not be negative, got %d", b.maxIdleConnsPerHost)
+	}
+
+	for key, values := range b.headers {
+		if !validHeaderName(key) {
+			add("invalid request header name %q", key)
+		}
+		for _, value := range values {
+			if !validHeaderValue(value) {
+				add("invalid value for request header %q", key)
+			}
+		}
+	}
+	if !validHeaderValue(b.userAgent) {
+		add("invalid user agent %q", b.userAgent)
+	}
+	if b.headerMergePolicy != HeaderReplace && b.headerMergePolicy != HeaderAppend {
+		add("unknown header merge policy %d", b.headerMergePolicy)
+	}
+
+	if p := b.retryPolicy; p != nil {
+		if p.MaxAttempts < 0 {
+			add("retry policy max attempts must not be negative, got %d", p.MaxAttempts)
+		}
+		if p.InitialBackoff < 0 || p.MaxBackoff < 0 || p.MaxElapsedTime < 0 || p.MaxRetryAfter < 0 {
+			add("retry policy durations must not be negative")
+		}
+		if p.InitialBackoff > 0 && p.MaxBackoff > 0 && p.InitialBackoff > p.MaxBackoff {
+			add("retry policy initial backoff %s exceeds max backoff %s", p.InitialBackoff, p.MaxBackoff)
+		}
+		for _, code := range p.StatusCodes {
+			if code < 100 || code > 599 {
+				add("retry policy status code %d is not valid", code)
+			}
+		}
+	}
+
+	if p := b.circuitBreaker; p != nil {
+		if p.FailureThreshold < 0 || p.FailureThreshold > 1 {
+			add("circuit breaker failure threshold must be between 0 and 1, got %g", p.FailureThreshold)
+		}
+		if p.MinRequests < 0 || p.HalfOpenProbes < 0 {
+			add("circuit breaker request counts must not be negative")
+		}
+		if p.Interval < 0 || p.OpenDuration < 0 {
+			add("circuit breaker durations must not be negative")
+		}
+	}
+
+	if l := b.rateLimit; l != nil {
+		if l.Rate <= 0 {
+			add("rate limit rate must be greater than zero, got %g", l.Rate)
+		}
+		if l.Burst < 0 {
+			add("rate limit burst must not be negative, got %d", l.Burst)
+		}
+		switch {
+		case l.Scope < RateLimitClient || l.Scope > RateLimitEndpoint:
+			add("unknown rate limit scope %d", l.Scope)
+		case l.Scope == RateLimitEndpoint && len(l.Prefixes) == 0:
+			add("rate limit endpoint scope requires at least one prefix")
+		case l.Scope != RateLimitEndpoint && len(l.Prefixes) > 0:
+			add("rate limit prefixes are only used with the endpoint scope")
+		}
+	}
+
+	for i, m := range b.middleware {
+		if m == nil {
+			add("middleware %d is nil", i)
+		}
+	}
+
+	if c := b.compression; c != nil {
+		switch c.Encoding {
+		case "", EncodingGzip, EncodingDeflate, EncodingZstd:
+		default:
+			add("unsupported request compression encoding %q", c.Encoding)
+		}
+		if c.MinSize < 0 {
+			add("request compression min size must not be negative, got %d", c.MinSize)
+		}
+	}
+
+	if t := b.tlsConfig; t != nil {
+		if (t.CertFile == "") != (t.KeyFile == "") {
+			add("TLS client certificate requires both a cert file and a key file")
+		} else if _, err := newTLSConfig(t, nil); err != nil {
+			errs = append(errs, err)
+		}
+		if t.ReloadInterval < 0 {
+			add("TLS reload interval must not be negative, got %s", t.ReloadInterval)
+		} else if t.ReloadInterval > 0 && t.CertFile == "" && len(t.RootCAFiles) == 0 {
+			add("TLS reload interval requires a cert file or root CA files")
+		}
+		switch t.MinVersion {
+		case 0, tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13:
+		default:
+			add("unknown TLS min version 0x%04x", t.MinVersion)
+		}
+		for _, id := range t.CipherSuites {
+			if !knownCipherSuite(id) {
+				add("unknown TLS cipher suite 0x%04x", id)
+			}
+		}
+	}
+
+	if p := b.pins; p != nil {
+		for host, hashes := range p.Hosts {
+			if host == "" || net.ParseIP(host) == nil && strings.ContainsAny(host, ":/") {
+				add("certificate pin host %q must be a host name without a port", host)
+			}
+			for _, hash := range hashes {
+				sum, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(hash, "sha256/"))
+				if err != nil || len(sum) != sha256.Size {
+					add("certificate pin %q for host %q is not a base64 SHA-256 hash", hash, host)
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// knownCipherSuite reports whether id is a cipher suite implemented by the
+// standard library.
+func knownCipherSuite(id uint16) bool {
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		if suite.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// validHeaderName reports whether name is a valid header field name, which is
+// a non-empty token as defined by RFC 9110.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			continue
+		}
+		if !strings.ContainsRune("!#$%&'*+-.^_`|~", rune(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// validHeaderValue reports whether value is a valid header field value, which
+// must not contain control characters other than horizontal tab.
+func validHeaderValue(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c < ' ' && c != '\t' || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,198 @@
+package goclient
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tt := []struct {
+		name   string
+		build  *builder
+		expect []string
+	}{
+		{
+			name:  "Default",
+			build: &builder{},
+		},
+		{
+			name: "Valid",
+			build: &builder{
+				baseURL:           "https://foobar.com/v2",
+				headers:           http.Header{HeaderAuthorization: {"Basic token"}},
+				connectionTimeout: time.Second,
+				retryPolicy:       &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Minute},
+				circuitBreaker:    &CircuitBreakerPolicy{FailureThreshold: 1},
+				rateLimit:         &RateLimit{Rate: 10, Scope: RateLimitEndpoint, Prefixes: []string{"/v2"}},
+				compression:       &Compression{Encoding: EncodingZstd},
+			},
+		},
+		{
+			name:   "BaseURLScheme",
+			build:  &builder{baseURL: "foobar.com"},
+			expect: []string{`base URL "foobar.com" must have an http or https scheme`},
+		},
+		{
+			name:   "BaseURLHost",
+			build:  &builder{baseURL: "https:///v2"},
+			expect: []string{`base URL "https:///v2" must have a host`},
+		},
+		{
+			name:   "BaseURLQuery",
+			build:  &builder{baseURL: "https://foobar.com?id=1"},
+			expect: []string{"must not have a query or fragment"},
+		},
+		{
+			name:   "BaseURLMalformed",
+			build:  &builder{baseURL: "https://foo bar.com"},
+			expect: []string{"invalid base URL"},
+		},
+		{
+			name:  "NegativeTimeouts",
+			build: &builder{connectionTimeout: -time.Second, responseTimeout: -time.Second, maxIdleConnsPerHost: -1},
+			expect: []string{
+				"connection timeout must not be negative, got -1s",
+				"response timeout must not be negative, got -1s",
+				"max idle connections per host must not be negative, got -1",
+			},
+		},
+		{
+			name: "MalformedHeaders",
+			build: &builder{
+				headers:           http.Header{"Bad Name": {"x"}, "X-Value": {"a\r\nb"}},
+				userAgent:         "go\n",
+				headerMergePolicy: HeaderMergePolicy(5),
+			},
+			expect: []string{
+				`invalid request header name "Bad Name"`,
+				`invalid value for request header "X-Value"`,
+				`invalid user agent "go\n"`,
+				"unknown header merge policy 5",
+			},
+		},
+		{
+			name: "RetryPolicy",
+			build: &builder{retryPolicy: &RetryPolicy{
+				MaxAttempts:    -1,
+				InitialBackoff: time.Minute,
+				MaxBackoff:     time.Second,
+				MaxElapsedTime: -time.Second,
+				StatusCodes:    []int{42},
+			}},
+			expect: []string{
+				"retry policy max attempts must not be negative",
+				"retry policy durations must not be negative",
+				"retry policy initial backoff 1m0s exceeds max backoff 1s",
+				"retry policy status code 42 is not valid",
+			},
+		},
+		{
+			name:  "CircuitBreaker",
+			build: &builder{circuitBreaker: &CircuitBreakerPolicy{FailureThreshold: 1.5, MinRequests: -1, OpenDuration: -time.Second}},
+			expect: []string{
+				"circuit breaker failure threshold must be between 0 and 1, got 1.5",
+				"circuit breaker request counts must not be negative",
+				"circuit breaker durations must not be negative",
+			},
+		},
+		{
+			name:  "RateLimit",
+			build: &builder{rateLimit: &RateLimit{Burst: -1, Scope: RateLimitEndpoint}},
+			expect: []string{
+				"rate limit rate must be greater than zero, got 0",
+				"rate limit burst must not be negative, got -1",
+				"rate limit endpoint scope requires at least one prefix",
+			},
+		},
+		{
+			name:   "RateLimitPrefixes",
+			build:  &builder{rateLimit: &RateLimit{Rate: 1, Scope: RateLimitHost, Prefixes: []string{"/v2"}}},
+			expect: []string{"rate limit prefixes are only used with the endpoint scope"},
+		},
+		{
+			name:   "NilMiddleware",
+			build:  &builder{middleware: []Middleware{nil}},
+			expect: []string{"middleware 0 is nil"},
+		},
+		{
+			name:  "Compression",
+			build: &builder{compression: &Compression{Encoding: EncodingBrotli, MinSize: -1}},
+			expect: []string{
+				`unsupported request compression encoding "br"`,
+				"request compression min size must not be negative, got -1",
+			},
+		},
+		{
+			name: "TLSConfig",
+			build: &builder{tlsConfig: &TLSConfig{
+				CertFile:     "client.crt",
+				MinVersion:   0x0999,
+				CipherSuites: []uint16{tls.TLS_AES_128_GCM_SHA256, 0x0999},
+			}},
+			expect: []string{
+				"TLS client certificate requires both a cert file and a key file",
+				"unknown TLS min version 0x0999",
+				"unknown TLS cipher suite 0x0999",
+			},
+		},
+		{
+			name:   "TLSReloadInterval",
+			build:  &builder{tlsConfig: &TLSConfig{ReloadInterval: time.Minute}},
+			expect: []string{"TLS reload interval requires a cert file or root CA files"},
+		},
+		{
+			name: "CertificatePins",
+			build: &builder{pins: &CertificatePins{Hosts: map[string][]string{
+				"foobar.com:443": {"sha256/" + strings.Repeat("A", 43) + "="},
+				"::1":            {"not a hash"},
+			}}},
+			expect: []string{
+				`certificate pin host "foobar.com:443" must be a host name without a port`,
+				`certificate pin "not a hash" for host "::1" is not a base64 SHA-256 hash`,
+			},
+		},
+		{
+			name:   "TLSConfigFiles",
+			build:  &builder{tlsConfig: &TLSConfig{RootCAFiles: []string{"missing.pem"}}},
+			expect: []string{"load root CA"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.build.Validate()
+			if len(tc.expect) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			errs := err.(interface{ Unwrap() []error }).Unwrap()
+			assert.Len(t, errs, len(tc.expect))
+			for _, expect := range tc.expect {
+				assert.Contains(t, err.Error(), expect)
+			}
+		})
+	}
+}
+
+func TestBuildE(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		c, err := NewBuild().SetBaseURL("https://foobar.com").BuildE()
+		require.NoError(t, err)
+		assert.IsType(t, &client{}, c)
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		c, err := NewBuild().
+			SetBaseURL("foobar.com").
+			SetConnectionTimeout(-time.Second).
+			BuildE()
+		assert.Nil(t, c)
+		require.Error(t, err)
+		assert.Equal(t, 2, len(strings.Split(err.Error(), "\n")))
+	})
+}
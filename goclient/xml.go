@@ -0,0 +1,75 @@
+package goclient
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// encodeXml returns the XML encoding of body, preceded by an XML prolog that
+// declares the UTF-8 encoding.
+func encodeXml(body any) ([]byte, error) {
+	b, err := xml.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
+
+// decodeXml decodes the XML data into the value pointed to by target, using
+// the encoding declared in the XML prolog.
+func decodeXml(data []byte, target any) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charsetReader
+	return decoder.Decode(target)
+}
+
+// charsetReader returns a reader that converts input of the given charset to
+// UTF-8. It is only called for charsets other than UTF-8.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "l1":
+		return &latin1Reader{reader: bufio.NewReader(input)}, nil
+	}
+	return nil, fmt.Errorf("goclient: unsupported XML charset %q", charset)
+}
+
+// latin1Reader converts ISO-8859-1 input to UTF-8. Each byte of the input is
+// the code point of a single rune.
+type latin1Reader struct {
+	reader  io.ByteReader
+	pending []byte
+}
+
+// Read fills p with the UTF-8 encoding of the input.
+func (l *latin1Reader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(l.pending) > 0 {
+			c := copy(p[n:], l.pending)
+			l.pending = l.pending[c:]
+			n += c
+			continue
+		}
+		b, err := l.reader.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		if b < utf8.RuneSelf {
+			p[n] = b
+			n++
+			continue
+		}
+		l.pending = utf8.AppendRune(nil, rune(b))
+	}
+	return n, nil
+}
@@ -0,0 +1,88 @@
+package goclient
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockXml struct {
+	XMLName struct{} `xml:"student"`
+	ID      int      `xml:"id,attr"`
+	Name    string   `xml:"name"`
+}
+
+func TestEncodeXml(t *testing.T) {
+	have, err := encodeXml(&mockXml{ID: 1, Name: "foobar"})
+	require.NoError(t, err, "expected no errors")
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+`<student id="1"><name>foobar</name></student>`, string(have))
+
+	have, err = encodeXml(make(chan int))
+	assert.Error(t, err)
+	assert.Nil(t, have)
+}
+
+func TestDecodeXml(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     []byte
+		expect   mockXml
+		hasError bool
+	}{
+		{
+			name:   "NoProlog",
+			data:   []byte(`<student id="1"><name>foobar</name></student>`),
+			expect: mockXml{ID: 1, Name: "foobar"},
+		},
+		{
+			name:   "UTF8",
+			data:   []byte(`<?xml version="1.0" encoding="UTF-8"?><student id="1"><name>Zoë</name></student>`),
+			expect: mockXml{ID: 1, Name: "Zoë"},
+		},
+		{
+			name:   "ASCII",
+			data:   []byte(`<?xml version="1.0" encoding="US-ASCII"?><student id="1"><name>foobar</name></student>`),
+			expect: mockXml{ID: 1, Name: "foobar"},
+		},
+		{
+			name:   "Latin1",
+			data:   []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><student id=\"1\"><name>Zo\xeb</name></student>"),
+			expect: mockXml{ID: 1, Name: "Zoë"},
+		},
+		{
+			name:     "UnsupportedCharset",
+			data:     []byte(`<?xml version="1.0" encoding="Shift_JIS"?><student id="1"></student>`),
+			hasError: true,
+		},
+		{
+			name:     "Malformed",
+			data:     []byte(`<student`),
+			hasError: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var have mockXml
+			err := decodeXml(tc.data, &have)
+			if tc.hasError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err, "expected no errors")
+			assert.Equal(t, tc.expect, have)
+		})
+	}
+}
+
+func TestLatin1Reader(t *testing.T) {
+	input := strings.Repeat("caf\xe9 ", 100)
+	reader, err := charsetReader("latin1", strings.NewReader(input))
+	require.NoError(t, err, "expected no errors")
+
+	have, err := io.ReadAll(io.LimitReader(reader, 1<<20))
+	require.NoError(t, err, "expected no errors")
+	assert.Equal(t, strings.Repeat("café ", 100), string(have))
+}